@@ -10,6 +10,10 @@ import { Event } from '@/store/slices/eventSlice';
 import { useEffect, useState } from 'react';
 import { useToast } from '@/hooks/use-toast';
 
+// There is no card form yet, so bookings pay with a token from the
+// environment; the development server's fake provider accepts any token.
+const PAYMENT_TOKEN = import.meta.env.VITE_PAYMENT_TOKEN ?? 'tok_ok';
+
 interface EventCardProps {
   event: Event;
   showBookButton?: boolean; // Optional prop to control if book button should be shown
@@ -94,7 +98,11 @@ const EventCard = ({ event, showBookButton = true }: EventCardProps) => {
 
     try {
       setIsBookingThisEvent(true);
-      await dispatch(bookTicket(event.id)).unwrap();
+      await dispatch(bookTicket({
+        eventId: event.id,
+        quantity: 1,
+        payment_token: PAYMENT_TOKEN,
+      })).unwrap();
     } catch (error) {
       toast({
         title: "Booking Failed",
@@ -6,7 +6,7 @@ export interface User {
   id: string
   name: string
   email: string
-  role: 'user' | 'organizer'
+  role: 'user' | 'organizer' | 'staff'
   created_at: string
 }
 
@@ -27,7 +27,7 @@ export interface RegisterData {
   name: string
   email: string
   password: string
-  role: 'user' | 'organizer'
+  role: 'user' | 'organizer' | 'staff'
 }
 
 export interface AuthResponse {
@@ -8,11 +8,17 @@ export interface Event {
   title: string
   description: string
   date: string
+  end_date?: string
   location: string
+  timezone?: string
   price: number
   total_tickets: number
   available_tickets: number
   organizer_id: string
+  status: 'draft' | 'published' | 'sales_paused' | 'cancelled' | 'completed'
+  sales_start?: string
+  sales_end?: string
+  sales_status?: 'upcoming' | 'open' | 'closed'
   created_at: string
   updated_at: string
 }
@@ -28,7 +34,8 @@ export interface EventFormData {
 
 export interface EventsResponse {
   events: Event[]
-  count: number
+  total: number
+  next_cursor: string | null
 }
 
 export interface EventState {
@@ -61,6 +68,37 @@ const getAuthHeaders = (token: string) => ({
   'Authorization': `Bearer ${token}`,
 })
 
+// Error returned by the API, as opposed to a network failure
+class ApiError extends Error {}
+
+// The events listing is paged, so follow next_cursor until every matching
+// event has been loaded
+const fetchEventPages = async (query: Record<string, string>, headers: HeadersInit): Promise<EventsResponse> => {
+  const events: Event[] = []
+  let total = 0
+  let cursor: string | null = null
+  do {
+    const params = new URLSearchParams({ ...query, limit: '100' })
+    if (cursor) {
+      params.set('cursor', cursor)
+    }
+
+    const response = await fetch(`${API_BASE}/events?${params}`, { headers })
+    const data = await response.json()
+
+    if (!response.ok) {
+      throw new ApiError(data.error || 'Failed to fetch events')
+    }
+
+    const page = data as EventsResponse
+    events.push(...page.events)
+    total = page.total
+    cursor = page.next_cursor
+  } while (cursor)
+
+  return { events, total, next_cursor: null }
+}
+
 // Async Thunks
 export const fetchAllEvents = createAsyncThunk<
   EventsResponse,
@@ -68,19 +106,9 @@ export const fetchAllEvents = createAsyncThunk<
   { rejectValue: string }
 >('events/fetchAll', async (_, { rejectWithValue }) => {
   try {
-    const response = await fetch(`${API_BASE}/events`, {
-      headers: { 'Content-Type': 'application/json' },
-    })
-
-    const data = await response.json()
-
-    if (!response.ok) {
-      return rejectWithValue(data.error || 'Failed to fetch events')
-    }
-
-    return data
+    return await fetchEventPages({}, { 'Content-Type': 'application/json' })
   } catch (error) {
-    return rejectWithValue('Network error occurred')
+    return rejectWithValue(error instanceof ApiError ? error.message : 'Network error occurred')
   }
 })
 
@@ -165,7 +193,7 @@ export const updateEvent = createAsyncThunk<
 })
 
 export const deleteEvent = createAsyncThunk<
-  string,
+  { eventId: string; cancelled: boolean },
   string,
   { rejectValue: string; state: RootState }
 >('events/delete', async (eventId, { rejectWithValue, getState }) => {
@@ -180,12 +208,14 @@ export const deleteEvent = createAsyncThunk<
       headers: { 'Authorization': `Bearer ${auth.token}` },
     })
 
+    const data = await response.json()
+
     if (!response.ok) {
-      const data = await response.json()
       return rejectWithValue(data.error || 'Failed to delete event')
     }
 
-    return eventId
+    // Events with sales are cancelled instead of deleted
+    return { eventId, cancelled: data.cancelled_tickets !== undefined }
   } catch (error) {
     return rejectWithValue('Network error occurred')
   }
@@ -203,21 +233,14 @@ export const fetchMyEvents = createAsyncThunk<
       return rejectWithValue('No authentication token')
     }
 
-    const response = await fetch(`${API_BASE}/events`, {
-      headers: { 'Content-Type': 'application/json' },
-    })
-
-    const data = await response.json()
-
-    if (!response.ok) {
-      return rejectWithValue(data.error || 'Failed to fetch events')
-    }
-
-    // Filter events by organizer_id
-    const myEvents = data.events.filter((event: Event) => event.organizer_id === auth.user?.id)
-    return myEvents
+    // Signed in, the organizer's own drafts and past events are included
+    const page = await fetchEventPages(
+      { organizer_id: auth.user.id, include_past: 'true' },
+      getAuthHeaders(auth.token),
+    )
+    return page.events
   } catch (error) {
-    return rejectWithValue('Network error occurred')
+    return rejectWithValue(error instanceof ApiError ? error.message : 'Network error occurred')
   }
 })
 
@@ -384,17 +407,23 @@ const eventSlice = createSlice({
       })
       .addCase(deleteEvent.fulfilled, (state, action) => {
         state.isDeleting = false
-        const eventId = action.payload
+        const { eventId, cancelled } = action.payload
         
         // Remove from events array
         state.events = state.events.filter(event => event.id !== eventId)
         
-        // Remove from my events
-        state.myEvents = state.myEvents.filter(event => event.id !== eventId)
+        // Cancelled events stay in my events with their new status
+        if (cancelled) {
+          state.myEvents = state.myEvents.map(event =>
+            event.id === eventId ? { ...event, status: 'cancelled' } : event
+          )
+        } else {
+          state.myEvents = state.myEvents.filter(event => event.id !== eventId)
+        }
         
         // Clear current event if it was deleted
         if (state.currentEvent?.id === eventId) {
-          state.currentEvent = null
+          state.currentEvent = cancelled ? { ...state.currentEvent, status: 'cancelled' } : null
         }
         
         state.error = null
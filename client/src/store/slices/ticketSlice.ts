@@ -32,6 +32,39 @@ export interface TicketWithEvent {
   created_at: string
 }
 
+export interface BookTicketRequest {
+  eventId: string
+  quantity: number
+  payment_token: string
+  ticket_type_id?: string
+  promo_code?: string
+}
+
+export interface BookedTicket {
+  id: string
+  event_id: string
+  ticket_type?: string
+  qr_code: string
+  status: 'pending' | 'active' | 'used' | 'cancelled'
+  price: number
+  promo_code?: string
+  discount?: number
+  created_at: string
+}
+
+// The order is paid, or pending while the payment provider is still
+// processing the charge.
+export interface BookOrderResponse {
+  order_id: string
+  event_id: string
+  quantity: number
+  total_price: number
+  status: 'pending' | 'paid'
+  tickets: BookedTicket[]
+  message: string
+  created_at: string
+}
+
 export interface ValidateTicketRequest {
   qr_code: string
 }
@@ -66,10 +99,10 @@ const getAuthHeaders = (token: string) => ({
 
 // Async Thunks
 export const bookTicket = createAsyncThunk<
-  Ticket,
-  string, // eventId
+  BookOrderResponse,
+  BookTicketRequest,
   { rejectValue: string; state: RootState }
->('tickets/book', async (eventId, { rejectWithValue, getState }) => {
+>('tickets/book', async ({ eventId, ...request }, { rejectWithValue, getState }) => {
   try {
     const { auth } = getState()
     if (!auth.token) {
@@ -78,7 +111,8 @@ export const bookTicket = createAsyncThunk<
 
     const response = await fetch(`${API_BASE}/tickets/book/${eventId}`, {
       method: 'POST',
-      headers: { 'Authorization': `Bearer ${auth.token}` },
+      headers: getAuthHeaders(auth.token),
+      body: JSON.stringify(request),
     })
 
     const data = await response.json()
@@ -161,9 +195,8 @@ export const cancelTicket = createAsyncThunk<
       return rejectWithValue('No authentication token')
     }
 
-    // This endpoint might not exist in your API, adjust as needed
     const response = await fetch(`${API_BASE}/tickets/${ticketId}/cancel`, {
-      method: 'PUT',
+      method: 'POST',
       headers: { 'Authorization': `Bearer ${auth.token}` },
     })
 
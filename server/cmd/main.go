@@ -1,17 +1,23 @@
 package main
 
 import (
+	"context"
 	"log"
 	"server/config"
 	"server/database"
+	"server/jobs"
+	"server/payments"
+	"server/repository"
 	"server/routes"
-
-	"github.com/gin-gonic/gin"
+	"server/waitlist"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("Invalid configuration:", err)
+	}
 
 	// Connect to MongoDB
 	if err := database.Connect(cfg.MongoURI); err != nil {
@@ -19,32 +25,38 @@ func main() {
 	}
 	defer database.Disconnect()
 
-	// Setup Gin router
-	r := gin.Default()
+	store, err := repository.NewMongoStore(context.Background(), database.DB)
+	if err != nil {
+		log.Fatal("Failed to set up database:", err)
+	}
 
-	// Add CORS middleware (optional)
-	r.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
+	// Every part of the app offers released seats through one waitlist
+	offers := waitlist.NewService(store, cfg.WaitlistOfferTTL)
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
+	// Release seat holds that expire before checkout and offer their seats
+	// to the waitlist
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go jobs.NewHoldExpirer(store, offers, cfg.HoldSweepInterval).Run(ctx)
 
-		c.Next()
-	})
+	// Setup payments
+	provider, err := payments.NewProvider(cfg)
+	if err != nil {
+		log.Fatal("Failed to set up payments:", err)
+	}
+	paymentService := payments.NewService(store, provider, cfg.Currency, offers)
 
-	// Setup routes
-	routes.SetupAuthRoutes(r)
-	routes.SetupEventRoutes(r)
-	routes.SetupTicketRoutes(r)
+	// The fake gateway runs in-process, so hand its webhooks straight over
+	if fake, ok := provider.(*payments.FakeProvider); ok {
+		fake.Deliver = func(payload []byte, signature string) {
+			if err := paymentService.HandleWebhook(context.Background(), payload, signature); err != nil {
+				log.Printf("Error handling payment webhook: %v", err)
+			}
+		}
+	}
 
-	// Health check endpoint
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "OK", "message": "Event Ticketing API is running"})
-	})
+	// Setup Gin router
+	r := routes.NewRouter(store, paymentService, offers)
 
 	// Start server
 	log.Printf("Server starting on port %s", cfg.Port)
@@ -1,21 +1,104 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"time"
 )
 
+// defaultQRSigningSeed is only fit for local development: anyone who knows
+// it can mint valid tickets.
+const defaultQRSigningSeed = "your-qr-signing-seed-change-in-production"
+
+// defaultPaymentWebhookSecret is public too: it would let anyone forge
+// payment webhooks.
+const defaultPaymentWebhookSecret = "your-webhook-secret-change-in-production"
+
 type Config struct {
+	// Env is "development" for local work; any other value is treated as a
+	// production deployment
+	Env       string
 	MongoURI  string
 	JWTSecret string
 	Port      string
+	// HoldTTL is how long a seat hold keeps inventory before it expires
+	HoldTTL time.Duration
+	// HoldSweepInterval is how often expired holds are released
+	HoldSweepInterval time.Duration
+	// WaitlistOfferTTL is how long a waitlisted user has to book released
+	// seats before they are offered to the next person
+	WaitlistOfferTTL time.Duration
+	// PaymentProvider selects the payment gateway; only "fake" is built in
+	PaymentProvider      string
+	PaymentWebhookSecret string
+	// PaymentWebhookDelay is how long the fake gateway waits before
+	// delivering webhooks for delayed captures
+	PaymentWebhookDelay time.Duration
+	Currency            string
+	// QRSigningSeed derives the Ed25519 key that signs ticket QR codes
+	QRSigningSeed string
+	// QRValidityAfterEvent is how long after the event date a QR code
+	// remains valid
+	QRValidityAfterEvent time.Duration
+	// WalletOrganization is the issuer name shown on wallet passes
+	WalletOrganization string
+	// Apple Wallet passes are signed with the pass type certificate and key
+	// (PEM files) and chained to Apple's WWDR intermediate certificate
+	ApplePassTypeID   string
+	AppleTeamID       string
+	ApplePassCertFile string
+	ApplePassKeyFile  string
+	AppleWWDRCertFile string
+	// Google Wallet save links are JWTs signed with a service account key
+	GoogleWalletIssuerID       string
+	GoogleWalletServiceAccount string
+	GoogleWalletKeyFile        string
 }
 
 func Load() *Config {
 	return &Config{
-		MongoURI:  getEnv("MONGO_URI", "mongodb://localhost:27017/event_ticketing"),
-		JWTSecret: getEnv("JWT_SECRET", "your-super-secret-key-change-in-production"),
-		Port:      getEnv("PORT", "8080"),
+		Env:                  getEnv("APP_ENV", "development"),
+		MongoURI:             getEnv("MONGO_URI", "mongodb://localhost:27017/event_ticketing"),
+		JWTSecret:            getEnv("JWT_SECRET", "your-super-secret-key-change-in-production"),
+		Port:                 getEnv("PORT", "8080"),
+		HoldTTL:              getDurationEnv("HOLD_TTL", 10*time.Minute),
+		HoldSweepInterval:    getDurationEnv("HOLD_SWEEP_INTERVAL", 30*time.Second),
+		WaitlistOfferTTL:     getDurationEnv("WAITLIST_OFFER_TTL", 30*time.Minute),
+		PaymentProvider:      getEnv("PAYMENT_PROVIDER", "fake"),
+		PaymentWebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", defaultPaymentWebhookSecret),
+		PaymentWebhookDelay:  getDurationEnv("PAYMENT_WEBHOOK_DELAY", 5*time.Second),
+		Currency:             getEnv("CURRENCY", "usd"),
+		QRSigningSeed:        getEnv("QR_SIGNING_SEED", defaultQRSigningSeed),
+		QRValidityAfterEvent: getDurationEnv("QR_VALIDITY_AFTER_EVENT", 24*time.Hour),
+		WalletOrganization:   getEnv("WALLET_ORGANIZATION", "Event Ticketing"),
+		ApplePassTypeID:      getEnv("APPLE_PASS_TYPE_ID", ""),
+		AppleTeamID:          getEnv("APPLE_TEAM_ID", ""),
+		ApplePassCertFile:    getEnv("APPLE_PASS_CERT_FILE", ""),
+		ApplePassKeyFile:     getEnv("APPLE_PASS_KEY_FILE", ""),
+		AppleWWDRCertFile:    getEnv("APPLE_WWDR_CERT_FILE", ""),
+
+		GoogleWalletIssuerID:       getEnv("GOOGLE_WALLET_ISSUER_ID", ""),
+		GoogleWalletServiceAccount: getEnv("GOOGLE_WALLET_SERVICE_ACCOUNT", ""),
+		GoogleWalletKeyFile:        getEnv("GOOGLE_WALLET_KEY_FILE", ""),
+	}
+}
+
+// Validate refuses settings that are unsafe outside development.
+func (c *Config) Validate() error {
+	if c.Env == "development" {
+		return nil
+	}
+	if c.QRSigningSeed == defaultQRSigningSeed {
+		return errors.New("QR_SIGNING_SEED must be set outside development")
 	}
+	// The fake gateway accepts any token, so tickets would be issued free
+	if c.PaymentProvider == "fake" {
+		return errors.New("PAYMENT_PROVIDER must be a real gateway outside development")
+	}
+	if c.PaymentWebhookSecret == defaultPaymentWebhookSecret {
+		return errors.New("PAYMENT_WEBHOOK_SECRET must be set outside development")
+	}
+	return nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -24,3 +107,12 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,53 @@
+package config
+
+import "testing"
+
+// productionConfig returns settings that pass validation in production.
+func productionConfig() *Config {
+	return &Config{
+		Env:                  "production",
+		QRSigningSeed:        "a-real-secret",
+		PaymentProvider:      "stripe",
+		PaymentWebhookSecret: "a-real-webhook-secret",
+	}
+}
+
+func TestValidateQRSigningSeed(t *testing.T) {
+	cfg := &Config{Env: "development", QRSigningSeed: defaultQRSigningSeed}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("development with the default seed: %v", err)
+	}
+
+	cfg = productionConfig()
+	cfg.QRSigningSeed = defaultQRSigningSeed
+	if err := cfg.Validate(); err == nil {
+		t.Error("production accepted the default seed")
+	}
+
+	if err := productionConfig().Validate(); err != nil {
+		t.Errorf("production with its own secrets: %v", err)
+	}
+}
+
+func TestValidatePaymentSettings(t *testing.T) {
+	cfg := &Config{
+		Env:                  "development",
+		PaymentProvider:      "fake",
+		PaymentWebhookSecret: defaultPaymentWebhookSecret,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("development with the fake gateway: %v", err)
+	}
+
+	cfg = productionConfig()
+	cfg.PaymentProvider = "fake"
+	if err := cfg.Validate(); err == nil {
+		t.Error("production accepted the fake gateway")
+	}
+
+	cfg = productionConfig()
+	cfg.PaymentWebhookSecret = defaultPaymentWebhookSecret
+	if err := cfg.Validate(); err == nil {
+		t.Error("production accepted the default webhook secret")
+	}
+}
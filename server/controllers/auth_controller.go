@@ -3,18 +3,21 @@ package controllers
 import (
 	"context"
 	"net/http"
-	"server/database"
 	"server/models"
+	"server/repository"
 	"server/utils"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type AuthController struct{}
+type AuthController struct {
+	users repository.UserRepository
+}
+
+func NewAuthController(store *repository.Store) *AuthController {
+	return &AuthController{users: store.Users}
+}
 
 type RegisterRequest struct {
 	Name     string `json:"name" validate:"required"`
@@ -47,8 +50,8 @@ func (ac *AuthController) Register(c *gin.Context) {
 		return
 	}
 
-	if req.Role != "user" && req.Role != "organizer" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'user' or 'organizer'"})
+	if req.Role != "user" && req.Role != "organizer" && req.Role != "staff" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role must be 'user', 'organizer' or 'staff'"})
 		return
 	}
 
@@ -58,13 +61,15 @@ func (ac *AuthController) Register(c *gin.Context) {
 	}
 
 	// Check if user already exists
-	collection := database.GetCollection("users")
-	var existingUser models.User
-	err := collection.FindOne(context.Background(), bson.M{"email": req.Email}).Decode(&existingUser)
+	_, err := ac.users.FindByEmail(context.Background(), req.Email)
 	if err == nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "User with this email already exists"})
 		return
 	}
+	if err != repository.ErrNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
@@ -83,14 +88,11 @@ func (ac *AuthController) Register(c *gin.Context) {
 		UpdatedAt: time.Now(),
 	}
 
-	result, err := collection.InsertOne(context.Background(), user)
-	if err != nil {
+	if err := ac.users.Create(context.Background(), &user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
-	user.ID = result.InsertedID.(primitive.ObjectID)
-
 	// Generate JWT token
 	token, err := utils.GenerateToken(user.ID.Hex(), user.Role)
 	if err != nil {
@@ -119,11 +121,9 @@ func (ac *AuthController) Login(c *gin.Context) {
 	}
 
 	// Find user
-	collection := database.GetCollection("users")
-	var user models.User
-	err := collection.FindOne(context.Background(), bson.M{"email": req.Email}).Decode(&user)
+	user, err := ac.users.FindByEmail(context.Background(), req.Email)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
+		if err == repository.ErrNotFound {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
 			return
 		}
@@ -2,38 +2,84 @@ package controllers
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
-	"server/database"
 	"server/models"
+	"server/payments"
+	"server/repository"
+	"server/waitlist"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type EventController struct{}
+type EventController struct {
+	events        repository.EventRepository
+	tickets       repository.TicketRepository
+	holds         repository.HoldRepository
+	listings      repository.ListingRepository
+	staff         repository.StaffRepository
+	users         repository.UserRepository
+	promos        repository.PromoCodeRepository
+	notifications repository.NotificationRepository
+	payments      *payments.Service
+	waitlist      *waitlist.Service
+}
+
+func NewEventController(store *repository.Store, paymentService *payments.Service, offers *waitlist.Service) *EventController {
+	return &EventController{
+		events:        store.Events,
+		tickets:       store.Tickets,
+		holds:         store.Holds,
+		listings:      store.Listings,
+		staff:         store.Staff,
+		users:         store.Users,
+		promos:        store.Promos,
+		notifications: store.Notifications,
+		payments:      paymentService,
+		waitlist:      offers,
+	}
+}
 
+const (
+	defaultEventPageSize = 20
+	maxEventPageSize     = 100
+)
+
+// GetEvents lists events by date, one page at a time. The response carries
+// a next_cursor to pass back as ?cursor= while more events remain.
 func (ec *EventController) GetEvents(c *gin.Context) {
-	collection := database.GetCollection("events")
+	query, err := parseEventQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Find all events, sorted by date
-	cursor, err := collection.Find(context.Background(), bson.M{}, options.Find().SetSort(bson.D{{Key: "date", Value: 1}}))
+	page, err := ec.events.Search(context.Background(), query)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch events"})
 		return
 	}
-	defer cursor.Close(context.Background())
 
-	var events []models.Event
-	if err := cursor.All(context.Background(), &events); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode events"})
-		return
+	now := time.Now()
+	for i := range page.Events {
+		page.Events[i].SalesStatus = page.Events[i].CurrentSalesStatus(now)
+	}
+
+	response := gin.H{"events": page.Events, "total": page.Total, "next_cursor": nil}
+	if page.HasMore {
+		last := page.Events[len(page.Events)-1]
+		response["next_cursor"] = encodeEventCursor(models.EventCursor{Date: last.Date, ID: last.ID})
 	}
 
-	c.JSON(http.StatusOK, gin.H{"events": events})
+	c.JSON(http.StatusOK, response)
 }
 
 func (ec *EventController) GetEvent(c *gin.Context) {
@@ -44,11 +90,9 @@ func (ec *EventController) GetEvent(c *gin.Context) {
 		return
 	}
 
-	collection := database.GetCollection("events")
-	var event models.Event
-	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&event)
+	event, err := ec.events.FindByID(context.Background(), objectID)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
+		if err == repository.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
 			return
 		}
@@ -56,6 +100,15 @@ func (ec *EventController) GetEvent(c *gin.Context) {
 		return
 	}
 
+	// Drafts are only visible to their organizer
+	if event.CurrentStatus() == "draft" {
+		if callerID, ok := optionalUserID(c); !ok || callerID != event.OrganizerID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+	}
+
+	event.SalesStatus = event.CurrentSalesStatus(time.Now())
 	c.JSON(http.StatusOK, event)
 }
 
@@ -71,26 +124,87 @@ func (ec *EventController) CreateEvent(c *gin.Context) {
 	organizerObjectID, _ := primitive.ObjectIDFromHex(organizerID.(string))
 
 	event := models.Event{
-		Title:            req.Title,
-		Description:      req.Description,
-		Date:             req.Date,
-		Location:         req.Location,
-		Price:            req.Price,
-		TotalTickets:     req.TotalTickets,
-		AvailableTickets: req.TotalTickets,
-		OrganizerID:      organizerObjectID,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
+		Title:               req.Title,
+		Description:         req.Description,
+		Date:                req.Date,
+		EndDate:             req.EndDate,
+		Location:            req.Location,
+		Timezone:            req.Timezone,
+		Price:               req.Price,
+		TotalTickets:        req.TotalTickets,
+		AvailableTickets:    req.TotalTickets,
+		MaxPerOrder:         req.MaxPerOrder,
+		MaxPerUser:          req.MaxPerUser,
+		CancelDeadlineHours: req.CancelDeadlineHours,
+		MaxScans:            req.MaxScans,
+		ScanLimitPerDay:     req.ScanLimitPerDay,
+		ResaleEnabled:       req.ResaleEnabled,
+		ResaleMaxPercent:    req.ResaleMaxPercent,
+		ResaleFee:           req.ResaleFee,
+		Status:              req.Status,
+		SalesStart:          req.SalesStart,
+		SalesEnd:            req.SalesEnd,
+		OrganizerID:         organizerObjectID,
+		CreatedAt:           time.Now(),
+		UpdatedAt:           time.Now(),
 	}
 
-	collection := database.GetCollection("events")
-	result, err := collection.InsertOne(context.Background(), event)
-	if err != nil {
+	if event.MaxPerOrder < 0 || event.MaxPerUser < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Purchase limits cannot be negative"})
+		return
+	}
+
+	if event.CancelDeadlineHours < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cancellation deadline cannot be negative"})
+		return
+	}
+
+	if event.ResaleMaxPercent < 0 || event.ResaleFee < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Resale price cap and fee cannot be negative"})
+		return
+	}
+
+	if err := validateSchedule(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateSalesWindow(&event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Events go on sale straight away unless created as a draft
+	switch event.Status {
+	case "":
+		event.Status = "published"
+	case "draft", "published":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Events can only be created as draft or published"})
+		return
+	}
+
+	if len(req.TicketTypes) > 0 {
+		if err := validateTicketTypes(req.TicketTypes, nil); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Ticket types replace the single price and pool
+		event.TotalTickets = 0
+		for _, ticketTypeReq := range req.TicketTypes {
+			event.TicketTypes = append(event.TicketTypes, newTicketType(ticketTypeReq))
+			event.TotalTickets += ticketTypeReq.TotalTickets
+		}
+		event.AvailableTickets = event.TotalTickets
+		event.Price = lowestPrice(event.TicketTypes)
+	}
+
+	if err := ec.events.Create(context.Background(), &event); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create event"})
 		return
 	}
 
-	event.ID = result.InsertedID.(primitive.ObjectID)
 	c.JSON(http.StatusCreated, event)
 }
 
@@ -108,66 +222,268 @@ func (ec *EventController) UpdateEvent(c *gin.Context) {
 		return
 	}
 
-	// Get organizer ID from context
-	organizerID, _ := c.Get("userID")
-	organizerObjectID, _ := primitive.ObjectIDFromHex(organizerID.(string))
+	// Check if event exists and belongs to organizer
+	event, ok := ec.findOwnedEvent(c, objectID)
+	if !ok {
+		return
+	}
 
-	collection := database.GetCollection("events")
+	if status := event.CurrentStatus(); status == "cancelled" || status == "completed" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Event is " + status + " and can no longer be changed"})
+		return
+	}
 
-	// Check if event exists and belongs to organizer
-	var existingEvent models.Event
-	err = collection.FindOne(context.Background(), bson.M{"_id": objectID, "organizer_id": organizerObjectID}).Decode(&existingEvent)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found or unauthorized"})
+	if len(req.TicketTypes) > 0 || len(event.TicketTypes) > 0 {
+		if req.TotalTickets != nil || req.Price != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Price and capacity are set per ticket type for this event"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	}
+
+	if req.TotalTickets != nil && *req.TotalTickets <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Capacity must be greater than 0"})
 		return
 	}
 
-	// Build update document
-	update := bson.M{"updated_at": time.Now()}
+	if len(req.TicketTypes) > 0 {
+		if err := validateTicketTypes(req.TicketTypes, event.TicketTypes); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// Seats already sold from the single pool cannot be assigned to a
+		// ticket type, so switching over is only possible before sales start
+		if len(event.TicketTypes) == 0 && event.AvailableTickets != event.TotalTickets {
+			c.JSON(http.StatusConflict, gin.H{"error": "Ticket types cannot be added after tickets have been sold"})
+			return
+		}
+	}
+
+	// Capacity can only shrink by seats that have not been sold or held
+	changes := capacityChanges(event, req)
+	for _, change := range changes {
+		if change.delta < 0 && -change.delta > change.unsold {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": fmt.Sprintf("Capacity cannot be reduced below the %d tickets already sold or held", change.total-change.unsold),
+				"sold":  change.total - change.unsold,
+			})
+			return
+		}
+	}
+
+	// Apply the requested changes
+	event.UpdatedAt = time.Now()
 	if req.Title != nil {
-		update["title"] = *req.Title
+		event.Title = *req.Title
 	}
 	if req.Description != nil {
-		update["description"] = *req.Description
+		event.Description = *req.Description
 	}
 	if req.Date != nil {
-		update["date"] = *req.Date
+		event.Date = *req.Date
+	}
+	if req.EndDate != nil {
+		event.EndDate = req.EndDate
 	}
 	if req.Location != nil {
-		update["location"] = *req.Location
+		event.Location = *req.Location
+	}
+	if req.Timezone != nil {
+		event.Timezone = *req.Timezone
 	}
 	if req.Price != nil {
-		update["price"] = *req.Price
+		event.Price = *req.Price
+	}
+	if req.MaxPerOrder != nil {
+		event.MaxPerOrder = *req.MaxPerOrder
+	}
+	if req.MaxPerUser != nil {
+		event.MaxPerUser = *req.MaxPerUser
+	}
+	if req.CancelDeadlineHours != nil {
+		event.CancelDeadlineHours = *req.CancelDeadlineHours
+	}
+	if req.MaxScans != nil {
+		event.MaxScans = *req.MaxScans
 	}
-	if req.TotalTickets != nil {
-		// Update available tickets proportionally
-		soldTickets := existingEvent.TotalTickets - existingEvent.AvailableTickets
-		update["total_tickets"] = *req.TotalTickets
-		update["available_tickets"] = *req.TotalTickets - soldTickets
+	if req.ScanLimitPerDay != nil {
+		event.ScanLimitPerDay = *req.ScanLimitPerDay
+	}
+	if req.ResaleEnabled != nil {
+		event.ResaleEnabled = *req.ResaleEnabled
+	}
+	if req.ResaleMaxPercent != nil {
+		event.ResaleMaxPercent = *req.ResaleMaxPercent
+	}
+	if req.ResaleFee != nil {
+		event.ResaleFee = *req.ResaleFee
+	}
+	if event.MaxPerOrder < 0 || event.MaxPerUser < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Purchase limits cannot be negative"})
+		return
+	}
+	if event.CancelDeadlineHours < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cancellation deadline cannot be negative"})
+		return
+	}
+	if event.ResaleMaxPercent < 0 || event.ResaleFee < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Resale price cap and fee cannot be negative"})
+		return
+	}
+	if req.SalesStart != nil {
+		event.SalesStart = req.SalesStart
+	}
+	if req.SalesEnd != nil {
+		event.SalesEnd = req.SalesEnd
+	}
+	if err := validateSchedule(event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateSalesWindow(event); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.TicketTypes) > 0 {
+		event.Price = lowestPrice(mergeTicketTypes(event.TicketTypes, req.TicketTypes))
 	}
 
-	result, err := collection.UpdateOne(context.Background(), bson.M{"_id": objectID}, bson.M{"$set": update})
-	if err != nil {
+	// Change capacity before saving anything else so a booking that takes
+	// the seats in the meantime leaves the event untouched
+	if err := ec.adjustCapacities(event.ID, changes); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Tickets were sold while the capacity was being changed, please try again"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+	if err := ec.events.Update(context.Background(), event); err != nil {
+		// Capacity and the rest of the event change together or not at all
+		ec.restoreCapacities(event.ID, changes)
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
 		return
 	}
 
+	if len(req.TicketTypes) > 0 {
+		if err := ec.applyTicketTypes(event, req.TicketTypes); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update ticket types"})
+			return
+		}
+	}
+
 	// Fetch updated event
-	var updatedEvent models.Event
-	collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&updatedEvent)
+	updatedEvent, err := ec.events.FindByID(context.Background(), objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Added capacity goes to the waitlist first
+	if req.TotalTickets != nil || len(req.TicketTypes) > 0 {
+		offered, err := ec.waitlist.OfferEventSeats(context.Background(), updatedEvent)
+		if err != nil {
+			log.Printf("Error offering seats of event %s to the waitlist: %v", objectID.Hex(), err)
+		}
+		if offered > 0 {
+			if updatedEvent, err = ec.events.FindByID(context.Background(), objectID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+		}
+	}
 
 	c.JSON(http.StatusOK, updatedEvent)
 }
 
+// UpdateEventStatus moves an event through its lifecycle: drafts are
+// published, sales can be paused and resumed, and events end up cancelled
+// or completed.
+func (ec *EventController) UpdateEventStatus(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req models.UpdateEventStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch req.Status {
+	case "draft", "published", "sales_paused", "cancelled", "completed":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event status"})
+		return
+	}
+
+	event, ok := ec.findOwnedEvent(c, objectID)
+	if !ok {
+		return
+	}
+
+	from := event.CurrentStatus()
+	if !event.CanTransitionTo(req.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Event cannot move from %s to %s", from, req.Status)})
+		return
+	}
+
+	if req.Status == "completed" && time.Now().Before(event.EndsAt()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Event cannot be completed before it has taken place"})
+		return
+	}
+
+	// Cancelling also cancels and refunds the tickets sold
+	if req.Status == "cancelled" {
+		if _, err := ec.cancelEvent(event); err != nil {
+			if err == repository.ErrConflict {
+				c.JSON(http.StatusConflict, gin.H{"error": "Event status was changed by another request"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel event"})
+			return
+		}
+	} else if err := ec.events.TransitionStatus(context.Background(), objectID, from, req.Status); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Event status was changed by another request"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event status"})
+		return
+	}
+
+	updatedEvent, err := ec.events.FindByID(context.Background(), objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Seats released while sales were paused have not been offered yet
+	if req.Status == "published" {
+		offered, err := ec.waitlist.OfferEventSeats(context.Background(), updatedEvent)
+		if err != nil {
+			log.Printf("Error offering seats of event %s to the waitlist: %v", objectID.Hex(), err)
+		}
+		if offered > 0 {
+			if updatedEvent, err = ec.events.FindByID(context.Background(), objectID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+				return
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, updatedEvent)
+}
+
+// DeleteEvent removes an event that has not sold any tickets. Events with
+// sales are cancelled instead, so their tickets and refunds keep pointing
+// at an existing event.
 func (ec *EventController) DeleteEvent(c *gin.Context) {
 	id := c.Param("id")
 	objectID, err := primitive.ObjectIDFromHex(id)
@@ -176,21 +492,825 @@ func (ec *EventController) DeleteEvent(c *gin.Context) {
 		return
 	}
 
-	// Get organizer ID from context
+	event, ok := ec.findOwnedEvent(c, objectID)
+	if !ok {
+		return
+	}
+
+	tickets, err := ec.tickets.ListByEvent(context.Background(), objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if len(tickets) > 0 && event.CurrentStatus() == "completed" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Completed events with sales cannot be deleted"})
+		return
+	}
+
+	// Sales close before anything is deleted, and the delete itself only
+	// goes through while no seat is sold or held, so a booking racing it
+	// ends up cancelled and refunded rather than pointing at nothing
+	previous := event.CurrentStatus()
+	if previous != "cancelled" {
+		if err := ec.events.TransitionStatus(context.Background(), event.ID, previous, "cancelled"); err != nil {
+			if err == repository.ErrConflict {
+				c.JSON(http.StatusConflict, gin.H{"error": "Event status was changed by another request"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
+			return
+		}
+		event.Status = "cancelled"
+	}
+
+	if err := ec.releaseHolds(context.Background(), event.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to release held seats"})
+		return
+	}
+
+	if len(tickets) == 0 {
+		err := ec.events.Delete(context.Background(), objectID)
+		if err == nil {
+			c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
+			return
+		}
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found or unauthorized"})
+			return
+		}
+		if err != repository.ErrConflict {
+			// Nothing was sold, so the event goes back on sale as it was
+			if previous != "cancelled" {
+				if err := ec.events.TransitionStatus(context.Background(), event.ID, "cancelled", previous); err != nil {
+					log.Printf("Error restoring status of event %s after a failed delete: %v", event.ID.Hex(), err)
+				}
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
+			return
+		}
+	}
+
+	cancellation, err := ec.cancelEvent(event)
+	if err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Event status was changed by another request"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Event has sales and was cancelled instead of deleted",
+		"cancelled_tickets": cancellation.cancelledTickets,
+		"refunds":           cancellation.refunds,
+	})
+}
+
+// releaseHolds gives back the seats of the event's active holds and closes
+// the waitlist offers they were made for, so neither a checkout nor the
+// hold expirer is left with a hold on a deleted event.
+func (ec *EventController) releaseHolds(ctx context.Context, eventID primitive.ObjectID) error {
+	holds, err := ec.holds.ListActiveByEvent(ctx, eventID)
+	if err != nil {
+		return err
+	}
+
+	for _, hold := range holds {
+		// A checkout that claims the hold first keeps it
+		err := ec.holds.TransitionStatus(ctx, hold.ID, "active", "released")
+		if err == repository.ErrConflict {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := ec.events.ReleaseTickets(ctx, hold.EventID, hold.TicketTypeID, hold.Quantity); err != nil {
+			return err
+		}
+		if err := ec.waitlist.CloseOffer(ctx, hold.ID, "expired"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventCancellation summarizes what cancelling an event did to its sales.
+type eventCancellation struct {
+	cancelledTickets int
+	refunds          []models.Refund
+}
+
+// cancelEvent marks the event cancelled, takes its tickets off the resale
+// marketplace, cancels them, refunds the ones that were paid for and
+// notifies their holders and whoever was refunded. Every ticket is
+// cancelled and notified about before a refund failure is returned, and
+// refunds are recorded once per ticket, so calling it again on a cancelled
+// event retries the refunds of tickets cancelled without one. Tickets whose
+// payment is still pending are refunded by the payment service once it is
+// captured.
+func (ec *EventController) cancelEvent(event *models.Event) (*eventCancellation, error) {
+	ctx := context.Background()
+
+	if from := event.CurrentStatus(); from != "cancelled" {
+		if err := ec.events.TransitionStatus(ctx, event.ID, from, "cancelled"); err != nil {
+			return nil, err
+		}
+	}
+
+	listings, err := ec.listings.ListActiveByEvent(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, listing := range listings {
+		// Listings being bought are refunded when their ticket cannot change hands
+		if err := ec.listings.TransitionStatus(ctx, listing.ID, "active", "cancelled"); err != nil && err != repository.ErrConflict {
+			return nil, err
+		}
+	}
+
+	tickets, err := ec.tickets.ListByEvent(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	cancellation := &eventCancellation{refunds: []models.Refund{}}
+	holders := map[primitive.ObjectID]bool{}
+	refunded := map[primitive.ObjectID]bool{}
+	var refundErr error
+	for i := range tickets {
+		ticket := &tickets[i]
+		cancelled := false
+		switch ticket.Status {
+		case "active", "pending":
+			err := ec.tickets.TransitionStatus(ctx, ticket.ID, ticket.Status, "cancelled")
+			if err == repository.ErrConflict {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			cancellation.cancelledTickets++
+			holders[ticket.UserID] = true
+			cancelled = true
+		case "cancelled":
+			// Possibly cancelled by an earlier attempt that failed to refund it
+		default:
+			continue
+		}
+
+		refund, err := ec.payments.RefundTicket(ctx, ticket, "event cancelled by organizer")
+		if err != nil {
+			if refundErr == nil {
+				refundErr = err
+			}
+			continue
+		}
+		if refund != nil {
+			cancellation.refunds = append(cancellation.refunds, *refund)
+			if cancelled {
+				refunded[refund.UserID] = true
+			}
+		}
+	}
+
+	// Refunds go to whoever paid last, who is not always the holder
+	recipients := map[primitive.ObjectID]string{}
+	for userID := range holders {
+		if refunded[userID] {
+			recipients[userID] = "Your tickets have been cancelled and what you paid for them has been refunded."
+		} else {
+			recipients[userID] = "Your tickets have been cancelled. Tickets you were given are refunded to whoever paid for them."
+		}
+	}
+	for userID := range refunded {
+		if !holders[userID] {
+			recipients[userID] = "Tickets you paid for and passed on have been cancelled and refunded to you."
+		}
+	}
+
+	for userID, detail := range recipients {
+		message := fmt.Sprintf("%s on %s has been cancelled. %s", event.Title, event.Date.Format("2 January 2006"), detail)
+		notification := models.Notification{
+			UserID:    userID,
+			EventID:   event.ID,
+			Kind:      "event_cancelled",
+			Message:   message,
+			CreatedAt: time.Now(),
+		}
+		if err := ec.notifications.Create(ctx, &notification); err != nil {
+			log.Printf("Error notifying user %s of the cancellation of event %s: %v", userID.Hex(), event.ID.Hex(), err)
+		}
+	}
+
+	if refundErr != nil {
+		return nil, refundErr
+	}
+	return cancellation, nil
+}
+
+// InviteStaff invites a staff account, by email, to validate tickets for
+// the event. The person does not need to have registered yet; the
+// assignment takes effect once they accept with the returned token.
+func (ec *EventController) InviteStaff(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req models.InviteStaffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is required"})
+		return
+	}
+
+	event, ok := ec.findOwnedEvent(c, objectID)
+	if !ok {
+		return
+	}
+
+	// An existing account has to be a staff account to be assigned
+	user, err := ec.users.FindByEmail(context.Background(), email)
+	if err != nil && err != repository.ErrNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if err == nil && user.Role != "staff" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a staff account"})
+		return
+	}
+
 	organizerID, _ := c.Get("userID")
 	organizerObjectID, _ := primitive.ObjectIDFromHex(organizerID.(string))
 
-	collection := database.GetCollection("events")
-	result, err := collection.DeleteOne(context.Background(), bson.M{"_id": objectID, "organizer_id": organizerObjectID})
+	// The token is handed to the invitee by the organizer and only its hash
+	// is kept, so the assignment cannot be claimed by just registering with
+	// the email
+	token, err := newInviteToken()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete event"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite staff"})
 		return
 	}
 
-	if result.DeletedCount == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found or unauthorized"})
+	assignment := models.StaffAssignment{
+		EventID:         event.ID,
+		Email:           email,
+		InvitedBy:       organizerObjectID,
+		InviteTokenHash: hashInviteToken(token),
+		CreatedAt:       time.Now(),
+	}
+	if err := ec.staff.Create(context.Background(), &assignment); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Staff member is already assigned to this event"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to invite staff"})
+		return
+	}
+
+	assignment.InviteToken = token
+	c.JSON(http.StatusCreated, assignment)
+}
+
+func (ec *EventController) ListStaff(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	if _, ok := ec.findOwnedEvent(c, objectID); !ok {
+		return
+	}
+
+	assignments, err := ec.staff.ListByEvent(context.Background(), objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch staff"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"staff": assignments})
+}
+
+func (ec *EventController) RemoveStaff(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	assignmentID, err := primitive.ObjectIDFromHex(c.Param("staffId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid staff assignment ID"})
+		return
+	}
+
+	if _, ok := ec.findOwnedEvent(c, objectID); !ok {
+		return
+	}
+
+	if err := ec.staff.Delete(context.Background(), objectID, assignmentID); err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Staff assignment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove staff"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Staff member removed successfully"})
+}
+
+// CreatePromoCode adds a discount code to one of the organizer's events.
+func (ec *EventController) CreatePromoCode(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req models.CreatePromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	if code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Code is required"})
+		return
+	}
+
+	switch req.DiscountType {
+	case "percent":
+		if req.Amount <= 0 || req.Amount > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Percentage discounts must be between 0 and 100"})
+			return
+		}
+	case "fixed":
+		if req.Amount <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Discount amount must be greater than 0"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Discount type must be 'percent' or 'fixed'"})
+		return
+	}
+
+	if req.MaxUses < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Max uses cannot be negative"})
+		return
+	}
+
+	if req.ValidFrom != nil && req.ValidUntil != nil && req.ValidUntil.Before(*req.ValidFrom) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Valid until must be after valid from"})
+		return
+	}
+
+	event, ok := ec.findOwnedEvent(c, objectID)
+	if !ok {
+		return
+	}
+
+	for _, ticketTypeID := range req.TicketTypeIDs {
+		if event.FindTicketType(ticketTypeID) == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket type"})
+			return
+		}
+	}
+
+	promo := models.PromoCode{
+		EventID:       event.ID,
+		Code:          code,
+		DiscountType:  req.DiscountType,
+		Amount:        req.Amount,
+		MaxUses:       req.MaxUses,
+		ValidFrom:     req.ValidFrom,
+		ValidUntil:    req.ValidUntil,
+		TicketTypeIDs: req.TicketTypeIDs,
+		Active:        true,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := ec.promos.Create(context.Background(), &promo); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Promo code already exists for this event"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create promo code"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, promo)
+}
+
+func (ec *EventController) ListPromoCodes(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	if _, ok := ec.findOwnedEvent(c, objectID); !ok {
+		return
+	}
+
+	promos, err := ec.promos.ListByEvent(context.Background(), objectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch promo codes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promo_codes": promos})
+}
+
+// DeactivatePromoCode stops a code from being redeemed. It is kept so the
+// tickets it discounted still refer to it.
+func (ec *EventController) DeactivatePromoCode(c *gin.Context) {
+	objectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	promoID, err := primitive.ObjectIDFromHex(c.Param("promoId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promo code ID"})
+		return
+	}
+
+	if _, ok := ec.findOwnedEvent(c, objectID); !ok {
+		return
+	}
+
+	if err := ec.promos.Deactivate(context.Background(), objectID, promoID); err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Promo code not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deactivate promo code"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Event deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Promo code deactivated successfully"})
+}
+
+// findOwnedEvent loads the event and checks it belongs to the calling
+// organizer, writing the error response itself when it does not.
+func (ec *EventController) findOwnedEvent(c *gin.Context, eventID primitive.ObjectID) (*models.Event, bool) {
+	// Get organizer ID from context
+	organizerID, _ := c.Get("userID")
+	organizerObjectID, _ := primitive.ObjectIDFromHex(organizerID.(string))
+
+	event, err := ec.events.FindByID(context.Background(), eventID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found or unauthorized"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+
+	if event.OrganizerID != organizerObjectID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found or unauthorized"})
+		return nil, false
+	}
+
+	return event, true
+}
+
+// applyTicketTypes persists the ticket type changes of an update request.
+// Capacity changes go through AdjustCapacity so they stay atomic with
+// concurrent bookings.
+func (ec *EventController) applyTicketTypes(event *models.Event, reqs []models.TicketTypeRequest) error {
+	for _, req := range reqs {
+		if req.ID == nil {
+			if err := ec.events.AddTicketType(context.Background(), event.ID, newTicketType(req)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		existing := event.FindTicketType(*req.ID)
+		ticketType := *existing
+		ticketType.Name = req.Name
+		ticketType.Price = req.Price
+		if err := ec.events.UpdateTicketType(context.Background(), event.ID, ticketType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// capacityChange is a change to the capacity of an event's single pool, or
+// of one of its ticket types, along with the counts it was worked out from.
+type capacityChange struct {
+	ticketTypeID primitive.ObjectID
+	delta        int
+	total        int
+	unsold       int
+}
+
+// capacityChanges works out the capacity changes an update makes, with the
+// reductions first.
+func capacityChanges(event *models.Event, req models.UpdateEventRequest) []capacityChange {
+	var changes []capacityChange
+	if req.TotalTickets != nil && *req.TotalTickets != event.TotalTickets {
+		changes = append(changes, capacityChange{
+			delta:  *req.TotalTickets - event.TotalTickets,
+			total:  event.TotalTickets,
+			unsold: event.AvailableTickets,
+		})
+	}
+
+	// An event switching to ticket types drops its unsold single pool
+	if len(req.TicketTypes) > 0 && len(event.TicketTypes) == 0 && event.TotalTickets > 0 {
+		changes = append(changes, capacityChange{
+			delta:  -event.TotalTickets,
+			total:  event.TotalTickets,
+			unsold: event.AvailableTickets,
+		})
+	}
+
+	for _, ticketTypeReq := range req.TicketTypes {
+		if ticketTypeReq.ID == nil {
+			continue
+		}
+		existing := event.FindTicketType(*ticketTypeReq.ID)
+		if delta := ticketTypeReq.TotalTickets - existing.TotalTickets; delta != 0 {
+			changes = append(changes, capacityChange{
+				ticketTypeID: existing.ID,
+				delta:        delta,
+				total:        existing.TotalTickets,
+				unsold:       existing.AvailableTickets,
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].delta < 0 && changes[j].delta >= 0 })
+	return changes
+}
+
+// adjustCapacities applies the changes in order. When one of them cannot be
+// made, those already applied are undone; as reductions come first, undoing
+// them only ever adds seats back.
+func (ec *EventController) adjustCapacities(eventID primitive.ObjectID, changes []capacityChange) error {
+	for i, change := range changes {
+		if err := ec.events.AdjustCapacity(context.Background(), eventID, change.ticketTypeID, change.delta); err != nil {
+			ec.restoreCapacities(eventID, changes[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreCapacities undoes capacity changes that were applied. Additions
+// are only taken back while their seats are unsold, so one that was
+// already booked is logged and left in place.
+func (ec *EventController) restoreCapacities(eventID primitive.ObjectID, applied []capacityChange) {
+	for _, change := range applied {
+		if err := ec.events.AdjustCapacity(context.Background(), eventID, change.ticketTypeID, -change.delta); err != nil {
+			log.Printf("Error restoring capacity of event %s: %v", eventID.Hex(), err)
+		}
+	}
+}
+
+// validateSchedule checks that a multi-day event ends after it starts and
+// that its timezone is known.
+func validateSchedule(event *models.Event) error {
+	if event.EndDate != nil && !event.EndDate.After(event.Date) {
+		return errors.New("Event must end after it starts")
+	}
+	if event.Timezone != "" {
+		if _, err := time.LoadLocation(event.Timezone); err != nil {
+			return errors.New("Unknown timezone")
+		}
+	}
+	return nil
+}
+
+// validateSalesWindow checks that the event's sales window is in order and
+// closes no later than the event starts.
+func validateSalesWindow(event *models.Event) error {
+	if event.SalesStart != nil && event.SalesEnd != nil && !event.SalesEnd.After(*event.SalesStart) {
+		return errors.New("Sales must end after they start")
+	}
+	if event.SalesStart != nil && !event.SalesStart.Before(event.Date) {
+		return errors.New("Sales must start before the event")
+	}
+	if event.SalesEnd != nil && event.SalesEnd.After(event.Date) {
+		return errors.New("Sales cannot end after the event starts")
+	}
+	return nil
+}
+
+// validateTicketTypes checks the requested ticket types against each other
+// and against the event's existing ones.
+func validateTicketTypes(reqs []models.TicketTypeRequest, existing []models.TicketType) error {
+	for _, req := range reqs {
+		if req.Name == "" {
+			return errors.New("Ticket type name is required")
+		}
+		if req.Price < 0 {
+			return errors.New("Ticket type price cannot be negative")
+		}
+		if req.TotalTickets <= 0 {
+			return errors.New("Ticket type capacity must be greater than 0")
+		}
+		if req.ID != nil && !containsTicketType(existing, *req.ID) {
+			return fmt.Errorf("Ticket type %s not found", req.ID.Hex())
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, ticketType := range mergeTicketTypes(existing, reqs) {
+		key := strings.ToLower(ticketType.Name)
+		if names[key] {
+			return fmt.Errorf("Duplicate ticket type name %q", ticketType.Name)
+		}
+		names[key] = true
+	}
+	return nil
+}
+
+func newTicketType(req models.TicketTypeRequest) models.TicketType {
+	return models.TicketType{
+		ID:               primitive.NewObjectID(),
+		Name:             req.Name,
+		Price:            req.Price,
+		TotalTickets:     req.TotalTickets,
+		AvailableTickets: req.TotalTickets,
+	}
+}
+
+// mergeTicketTypes returns the ticket types an event will have once reqs
+// has been applied. Only names and prices are meaningful in the result.
+func mergeTicketTypes(existing []models.TicketType, reqs []models.TicketTypeRequest) []models.TicketType {
+	merged := append([]models.TicketType(nil), existing...)
+	for _, req := range reqs {
+		if req.ID == nil {
+			merged = append(merged, models.TicketType{Name: req.Name, Price: req.Price})
+			continue
+		}
+		for i := range merged {
+			if merged[i].ID == *req.ID {
+				merged[i].Name = req.Name
+				merged[i].Price = req.Price
+			}
+		}
+	}
+	return merged
+}
+
+func containsTicketType(ticketTypes []models.TicketType, id primitive.ObjectID) bool {
+	for _, ticketType := range ticketTypes {
+		if ticketType.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEventQuery reads the listing filters from the query string.
+func parseEventQuery(c *gin.Context) (models.EventQuery, error) {
+	query := models.EventQuery{
+		Location: strings.TrimSpace(c.Query("location")),
+		Search:   strings.TrimSpace(c.Query("q")),
+		Limit:    defaultEventPageSize,
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxEventPageSize {
+			return query, fmt.Errorf("Limit must be between 1 and %d", maxEventPageSize)
+		}
+		query.Limit = limit
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := decodeEventCursor(raw)
+		if err != nil {
+			return query, errors.New("Invalid cursor")
+		}
+		query.After = &cursor
+	}
+
+	for _, param := range []struct {
+		name string
+		dest **time.Time
+	}{{"from", &query.From}, {"to", &query.To}} {
+		if raw := c.Query(param.name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return query, fmt.Errorf("Invalid %s date, expected RFC 3339", param.name)
+			}
+			*param.dest = &t
+		}
+	}
+	// Events that have taken place are left out unless asked for
+	if query.From == nil && c.Query("include_past") != "true" {
+		now := time.Now()
+		query.From = &now
+	}
+	if query.From != nil && query.To != nil && query.To.Before(*query.From) {
+		return query, errors.New("The to date must not be before the from date")
+	}
+
+	for _, param := range []struct {
+		name string
+		dest **float64
+	}{{"min_price", &query.MinPrice}, {"max_price", &query.MaxPrice}} {
+		if raw := c.Query(param.name); raw != "" {
+			price, err := strconv.ParseFloat(raw, 64)
+			if err != nil || price < 0 {
+				return query, fmt.Errorf("Invalid %s", param.name)
+			}
+			*param.dest = &price
+		}
+	}
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MaxPrice < *query.MinPrice {
+		return query, errors.New("The maximum price must not be below the minimum price")
+	}
+
+	if raw := c.Query("organizer_id"); raw != "" {
+		organizerID, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			return query, errors.New("Invalid organizer ID")
+		}
+		query.OrganizerID = &organizerID
+
+		// Organizers listing their own events see their drafts too
+		if callerID, ok := optionalUserID(c); ok && callerID == organizerID {
+			query.IncludeDrafts = true
+		}
+	}
+
+	if raw := c.Query("available"); raw != "" {
+		available, err := strconv.ParseBool(raw)
+		if err != nil {
+			return query, errors.New("Available must be true or false")
+		}
+		query.Available = &available
+	}
+
+	return query, nil
+}
+
+// optionalUserID is the caller's ID on routes where signing in is
+// optional.
+func optionalUserID(c *gin.Context) (primitive.ObjectID, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		return primitive.NilObjectID, false
+	}
+	userObjectID, err := primitive.ObjectIDFromHex(userID.(string))
+	return userObjectID, err == nil
+}
+
+// encodeEventCursor packs the position of the last event on a page into an
+// opaque token.
+func encodeEventCursor(cursor models.EventCursor) string {
+	raw := strconv.FormatInt(cursor.Date.UnixNano(), 10) + ":" + cursor.ID.Hex()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeEventCursor(token string) (models.EventCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return models.EventCursor{}, err
+	}
+	nanos, hex, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return models.EventCursor{}, errors.New("malformed cursor")
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return models.EventCursor{}, err
+	}
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return models.EventCursor{}, err
+	}
+	return models.EventCursor{Date: time.Unix(0, unixNano), ID: id}, nil
+}
+
+// lowestPrice is shown as the event's "from" price when it has ticket types.
+func lowestPrice(ticketTypes []models.TicketType) float64 {
+	lowest := ticketTypes[0].Price
+	for _, ticketType := range ticketTypes[1:] {
+		if ticketType.Price < lowest {
+			lowest = ticketType.Price
+		}
+	}
+	return lowest
 }
@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"server/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEventCursorRoundTrip(t *testing.T) {
+	cursor := models.EventCursor{Date: time.Date(2035, 6, 1, 20, 0, 0, 123, time.UTC), ID: primitive.NewObjectID()}
+
+	decoded, err := decodeEventCursor(encodeEventCursor(cursor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Date.Equal(cursor.Date) || decoded.ID != cursor.ID {
+		t.Errorf("decoded %+v, want %+v", decoded, cursor)
+	}
+}
+
+func TestTamperedEventCursors(t *testing.T) {
+	token := encodeEventCursor(models.EventCursor{Date: time.Now(), ID: primitive.NewObjectID()})
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	for name, tampered := range map[string]string{
+		"not base64":  "%%%",
+		"truncated":   token[:len(token)-3],
+		"no id":       encode("1780344000000000000"),
+		"bad date":    encode("yesterday:" + primitive.NewObjectID().Hex()),
+		"bad id":      encode("1780344000000000000:not-an-object-id"),
+		"padded":      token + "==",
+		"empty parts": encode(":"),
+	} {
+		if _, err := decodeEventCursor(tampered); err == nil {
+			t.Errorf("%s: cursor %q was accepted", name, tampered)
+		}
+	}
+}
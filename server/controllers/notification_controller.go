@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"server/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type NotificationController struct {
+	notifications repository.NotificationRepository
+}
+
+func NewNotificationController(store *repository.Store) *NotificationController {
+	return &NotificationController{notifications: store.Notifications}
+}
+
+// GetMyNotifications lists the caller's notifications, newest first.
+func (nc *NotificationController) GetMyNotifications(c *gin.Context) {
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	notifications, err := nc.notifications.ListByUser(context.Background(), userObjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications})
+}
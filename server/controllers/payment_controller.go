@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"server/payments"
+	"server/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentController struct {
+	payments *payments.Service
+}
+
+func NewPaymentController(paymentService *payments.Service) *PaymentController {
+	return &PaymentController{payments: paymentService}
+}
+
+func (pc *PaymentController) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	err = pc.payments.HandleWebhook(context.Background(), payload, c.GetHeader("X-Payment-Signature"))
+	if err != nil {
+		if err == payments.ErrInvalidSignature {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+			return
+		}
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook processed"})
+}
@@ -0,0 +1,369 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"server/models"
+	"server/payments"
+	"server/repository"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ResaleController runs the official resale marketplace. Prices are capped
+// by the organizer and buyers pay the organizer's fee on top; the ticket
+// only changes hands once the buyer's payment has been captured.
+type ResaleController struct {
+	events   repository.EventRepository
+	tickets  repository.TicketRepository
+	orders   repository.OrderRepository
+	listings repository.ListingRepository
+	payouts  repository.PayoutRepository
+	payments *payments.Service
+}
+
+func NewResaleController(store *repository.Store, paymentService *payments.Service) *ResaleController {
+	return &ResaleController{
+		events:   store.Events,
+		tickets:  store.Tickets,
+		orders:   store.Orders,
+		listings: store.Listings,
+		payouts:  store.Payouts,
+		payments: paymentService,
+	}
+}
+
+// CreateListing puts one of the caller's tickets up for resale.
+func (rc *ResaleController) CreateListing(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.CreateListingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	ticket, err := rc.tickets.FindByID(context.Background(), ticketID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Only the owner may sell; anyone else is told it does not exist
+	if ticket.UserID != userObjectID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		return
+	}
+
+	if ticket.Status != "active" || ticket.ScanCount > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only active, unused tickets can be listed for resale"})
+		return
+	}
+
+	event, err := rc.events.FindByID(context.Background(), ticket.EventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !event.ResaleEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Resale is not enabled for this event"})
+		return
+	}
+
+	if time.Now().After(event.Date) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event has already taken place"})
+		return
+	}
+
+	if req.Price < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Price cannot be negative"})
+		return
+	}
+
+	maxPrice := event.MaxResalePrice(ticket.TicketTypeID)
+	if req.Price > maxPrice {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":     "Price exceeds the maximum resale price for this ticket",
+			"max_price": maxPrice,
+		})
+		return
+	}
+
+	listing := models.Listing{
+		TicketID:     ticket.ID,
+		EventID:      ticket.EventID,
+		TicketTypeID: ticket.TicketTypeID,
+		SellerID:     userObjectID,
+		Price:        req.Price,
+		Fee:          event.ResaleFee,
+		Status:       "active",
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if err := rc.listings.Create(context.Background(), &listing); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Ticket is already listed for resale"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create listing"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, listing)
+}
+
+// GetListings lists the tickets on sale for an event, cheapest first.
+func (rc *ResaleController) GetListings(c *gin.Context) {
+	eventID, err := primitive.ObjectIDFromHex(c.Query("event_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	event, err := rc.events.FindByID(context.Background(), eventID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	listings := []models.Listing{}
+	if event.ResaleEnabled {
+		listings, err = rc.listings.ListActiveByEvent(context.Background(), event.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch listings"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"listings": listings})
+}
+
+// BuyListing charges the caller for a listed ticket. The listing is
+// reserved while the payment runs so no one else can buy it, and goes back
+// on sale if the payment fails.
+func (rc *ResaleController) BuyListing(c *gin.Context) {
+	listing, ok := rc.findListing(c)
+	if !ok {
+		return
+	}
+
+	var req models.BuyListingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	if listing.Status != "active" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Listing is no longer available"})
+		return
+	}
+
+	if listing.SellerID == userObjectID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot buy your own ticket"})
+		return
+	}
+
+	event, err := rc.events.FindByID(context.Background(), listing.EventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if !event.ResaleEnabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Resale is not enabled for this event"})
+		return
+	}
+
+	if time.Now().After(event.Date) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event has already taken place"})
+		return
+	}
+
+	// The organizer may have lowered the cap since the ticket was listed
+	if listing.Price > event.MaxResalePrice(listing.TicketTypeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Listing exceeds the event's resale price cap"})
+		return
+	}
+
+	if event.MaxPerUser > 0 {
+		owned, err := rc.tickets.CountByUserAndEvent(context.Background(), userObjectID, event.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if owned+1 > event.MaxPerUser {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Buying this ticket would exceed the per-user limit for this event"})
+			return
+		}
+	}
+
+	// Withdraw listings whose ticket was used or moved since it was listed
+	ticket, err := rc.tickets.FindByID(context.Background(), listing.TicketID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if ticket.UserID != listing.SellerID || ticket.Status != "active" || ticket.ScanCount > 0 {
+		rc.listings.TransitionStatus(context.Background(), listing.ID, "active", "cancelled")
+		c.JSON(http.StatusConflict, gin.H{"error": "Listing is no longer available"})
+		return
+	}
+
+	order := models.Order{
+		ID:           primitive.NewObjectID(),
+		EventID:      listing.EventID,
+		UserID:       userObjectID,
+		TicketTypeID: listing.TicketTypeID,
+		ListingID:    listing.ID,
+		TicketIDs:    []primitive.ObjectID{listing.TicketID},
+		Quantity:     1,
+		TotalPrice:   listing.Total(),
+		Status:       "pending",
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	// Reserving the listing first makes sure only one buyer is charged
+	if err := rc.listings.Reserve(context.Background(), listing.ID, userObjectID, order.ID); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Listing is no longer available"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reserve listing"})
+		return
+	}
+
+	if err := rc.orders.Create(context.Background(), &order); err != nil {
+		rc.listings.TransitionStatus(context.Background(), listing.ID, "reserved", "active")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+		return
+	}
+
+	status, err := rc.payments.Charge(context.Background(), &order, req.PaymentToken)
+	if err != nil {
+		if err == payments.ErrDeclined {
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": "Payment declined"})
+			return
+		}
+		if status == "failed" {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Payment failed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete purchase"})
+		return
+	}
+
+	order.Status = status
+	if status != "paid" {
+		c.JSON(http.StatusAccepted, gin.H{
+			"message": "Payment is being processed",
+			"order":   order,
+		})
+		return
+	}
+
+	ticket, err = rc.tickets.FindByID(context.Background(), listing.TicketID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// The seller's ticket changed while the payment went through and the
+	// payment has been refunded
+	if ticket.UserID != userObjectID {
+		c.JSON(http.StatusConflict, gin.H{"error": "Ticket could not be transferred, your payment has been refunded"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ticket purchased successfully",
+		"order":   order,
+		"ticket":  ticket,
+	})
+}
+
+// CancelListing takes the caller's ticket off the marketplace.
+func (rc *ResaleController) CancelListing(c *gin.Context) {
+	listing, ok := rc.findListing(c)
+	if !ok {
+		return
+	}
+
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	if listing.SellerID != userObjectID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+		return
+	}
+
+	if err := rc.listings.TransitionStatus(context.Background(), listing.ID, "active", "cancelled"); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Listing can no longer be cancelled"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel listing"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Listing cancelled"})
+}
+
+func (rc *ResaleController) findListing(c *gin.Context) (*models.Listing, bool) {
+	listingID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid listing ID"})
+		return nil, false
+	}
+
+	listing, err := rc.listings.FindByID(context.Background(), listingID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Listing not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+	return listing, true
+}
+
+// GetMyPayouts lists what the caller is owed for tickets sold on resale,
+// newest first.
+func (rc *ResaleController) GetMyPayouts(c *gin.Context) {
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	payouts, err := rc.payouts.ListByUser(context.Background(), userObjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payouts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payouts": payouts})
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"net/http"
+	"server/models"
+	"time"
+)
+
+// scanRejection explains why a scan could not be applied to a ticket.
+type scanRejection struct {
+	status  int
+	message string
+	// duplicate marks a ticket that was let in already, as opposed to one
+	// that was never valid for entry
+	duplicate bool
+}
+
+// applyScan applies scan to ticket according to the event's re-entry rules,
+// updating the ticket's scan state and log in place. It returns a rejection
+// and leaves the ticket untouched when the scan is not allowed.
+//
+// Events without re-entry admit each ticket once, after which it is used.
+// With re-entry, holders must be scanned out before being let in again, up
+// to AdmissionsAllowed times overall or per day in the venue's timezone. A
+// holder let in for the last time can still be scanned out.
+func applyScan(ticket *models.Ticket, event *models.Event, scan models.TicketScan) *scanRejection {
+	switch ticket.Status {
+	case "used":
+		if scan.Direction != "out" || !ticket.CheckedIn {
+			return &scanRejection{status: http.StatusConflict, message: "Ticket already used", duplicate: true}
+		}
+	case "cancelled":
+		return &scanRejection{status: http.StatusBadRequest, message: "Ticket is cancelled"}
+	case "pending":
+		return &scanRejection{status: http.StatusBadRequest, message: "Ticket has not been paid for"}
+	}
+
+	reentry := event.AllowsReentry()
+	venue := event.VenueTimeZone()
+	// A check-in from an earlier day does not carry over to a new one
+	checkedIn := ticket.CheckedIn && ticket.UsedAt != nil && (!event.ScanLimitPerDay || sameDay(*ticket.UsedAt, scan.ScannedAt, venue))
+
+	if scan.Direction == "out" {
+		if !reentry {
+			return &scanRejection{status: http.StatusBadRequest, message: "Re-entry is not allowed for this event"}
+		}
+		if !checkedIn {
+			return &scanRejection{status: http.StatusBadRequest, message: "Ticket holder is not checked in"}
+		}
+		ticket.CheckedIn = false
+		ticket.Scans = append(ticket.Scans, scan)
+		return nil
+	}
+
+	if reentry && checkedIn {
+		return &scanRejection{status: http.StatusConflict, message: "Ticket holder is already checked in", duplicate: true}
+	}
+
+	admissions := ticket.ScanCount
+	if event.ScanLimitPerDay {
+		admissions = 0
+		for _, previous := range ticket.Scans {
+			if previous.Direction == "in" && sameDay(previous.ScannedAt, scan.ScannedAt, venue) {
+				admissions++
+			}
+		}
+	}
+	if admissions >= event.AdmissionsAllowed() {
+		if event.ScanLimitPerDay {
+			return &scanRejection{status: http.StatusConflict, message: "Ticket has already been used today", duplicate: true}
+		}
+		return &scanRejection{status: http.StatusConflict, message: "Ticket already used", duplicate: true}
+	}
+
+	scannedAt := scan.ScannedAt
+	ticket.ScanCount++
+	ticket.CheckedIn = reentry
+	ticket.UsedAt = &scannedAt
+	ticket.UsedGate = scan.Gate
+	ticket.Scans = append(ticket.Scans, scan)
+	// Per-day tickets stay valid for the following days
+	if !event.ScanLimitPerDay && ticket.ScanCount >= event.AdmissionsAllowed() {
+		ticket.Status = "used"
+	}
+	return nil
+}
+
+// sameDay reports whether a and b fall on the same calendar day in loc.
+func sameDay(a, b time.Time, loc *time.Location) bool {
+	return a.In(loc).Format("2006-01-02") == b.In(loc).Format("2006-01-02")
+}
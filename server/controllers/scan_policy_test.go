@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"server/models"
+	"testing"
+	"time"
+)
+
+func scanAt(direction string, at time.Time) models.TicketScan {
+	return models.TicketScan{Direction: direction, ScannedAt: at}
+}
+
+func TestApplyScanExitAfterLastAdmission(t *testing.T) {
+	event := &models.Event{MaxScans: 2}
+	ticket := &models.Ticket{Status: "active"}
+	start := time.Date(2035, 6, 1, 18, 0, 0, 0, time.UTC)
+
+	for i, scan := range []models.TicketScan{
+		scanAt("in", start),
+		scanAt("out", start.Add(time.Hour)),
+		scanAt("in", start.Add(2*time.Hour)),
+	} {
+		if rejection := applyScan(ticket, event, scan); rejection != nil {
+			t.Fatalf("scan %d rejected: %s", i, rejection.message)
+		}
+	}
+	if ticket.Status != "used" || !ticket.CheckedIn {
+		t.Fatalf("after the last admission: status %q, checked in %v", ticket.Status, ticket.CheckedIn)
+	}
+
+	// The holder can still leave
+	if rejection := applyScan(ticket, event, scanAt("out", start.Add(3*time.Hour))); rejection != nil {
+		t.Fatalf("exit after the last admission rejected: %s", rejection.message)
+	}
+	if ticket.CheckedIn {
+		t.Error("holder still checked in after leaving")
+	}
+
+	// But not come back, or leave twice
+	if rejection := applyScan(ticket, event, scanAt("in", start.Add(4*time.Hour))); rejection == nil || !rejection.duplicate {
+		t.Errorf("re-admission after the last one: %+v", rejection)
+	}
+	if rejection := applyScan(ticket, event, scanAt("out", start.Add(4*time.Hour))); rejection == nil {
+		t.Error("second exit accepted")
+	}
+}
+
+func TestApplyScanCountsDaysInVenueTimezone(t *testing.T) {
+	event := &models.Event{ScanLimitPerDay: true, Timezone: "America/Los_Angeles"}
+	ticket := &models.Ticket{Status: "active"}
+
+	// 20:00 and 23:00 in Los Angeles are the same local day, though the
+	// second is already the next day in UTC
+	evening := time.Date(2035, 6, 1, 20, 0, 0, 0, event.VenueTimeZone())
+	if rejection := applyScan(ticket, event, scanAt("in", evening)); rejection != nil {
+		t.Fatalf("first admission rejected: %s", rejection.message)
+	}
+	if rejection := applyScan(ticket, event, scanAt("out", evening.Add(time.Hour))); rejection != nil {
+		t.Fatalf("exit rejected: %s", rejection.message)
+	}
+	if rejection := applyScan(ticket, event, scanAt("in", evening.Add(3*time.Hour))); rejection == nil {
+		t.Fatal("second admission on the same local day accepted")
+	}
+
+	// The next local morning is a new day
+	if rejection := applyScan(ticket, event, scanAt("in", evening.Add(14*time.Hour))); rejection != nil {
+		t.Fatalf("admission on the next day rejected: %s", rejection.message)
+	}
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"server/models"
+	"server/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Bookings and waitlist entries both ask for seats of one ticket type, and
+// an offer made from the waitlist is booked like any other hold, so the two
+// share these checks.
+
+// resolveTicketType finds the ticket type requested for the event and the
+// seats it has left, or returns why the request is invalid. Events without
+// ticket types sell from a single pool under the zero ID.
+func resolveTicketType(event *models.Event, requested *primitive.ObjectID) (primitive.ObjectID, int, string) {
+	if len(event.TicketTypes) == 0 {
+		if requested != nil {
+			return primitive.NilObjectID, 0, "This event has no ticket types"
+		}
+		return primitive.NilObjectID, event.AvailableTickets, ""
+	}
+
+	if requested == nil {
+		return primitive.NilObjectID, 0, "A ticket type is required for this event"
+	}
+	ticketType := event.FindTicketType(*requested)
+	if ticketType == nil {
+		return primitive.NilObjectID, 0, "Invalid ticket type"
+	}
+	return ticketType.ID, ticketType.AvailableTickets, ""
+}
+
+// purchaseLimitReason checks quantity more seats for the user against the
+// organizer's per-order and per-user limits, returning why they would be
+// exceeded. The per-user limit is best-effort: it counts what the user
+// already owns or holds, so simultaneous requests by the same user can each
+// pass the check before either has taken its seats.
+func purchaseLimitReason(ctx context.Context, tickets repository.TicketRepository, holds repository.HoldRepository, event *models.Event, userID primitive.ObjectID, quantity int) (string, error) {
+	if event.MaxPerOrder > 0 && quantity > event.MaxPerOrder {
+		return fmt.Sprintf("At most %d tickets can be booked per order", event.MaxPerOrder), nil
+	}
+
+	if event.MaxPerUser > 0 {
+		owned, err := tickets.CountByUserAndEvent(ctx, userID, event.ID)
+		if err != nil {
+			return "", err
+		}
+		held, err := holds.SumActiveByUserAndEvent(ctx, userID, event.ID)
+		if err != nil {
+			return "", err
+		}
+		if owned+held+quantity > event.MaxPerUser {
+			return fmt.Sprintf("At most %d tickets can be booked per user", event.MaxPerUser), nil
+		}
+	}
+	return "", nil
+}
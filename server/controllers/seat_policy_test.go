@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"server/models"
+	"server/repository"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestResolveTicketType(t *testing.T) {
+	vip := models.TicketType{ID: primitive.NewObjectID(), Name: "VIP", TotalTickets: 5, AvailableTickets: 3}
+	tiered := &models.Event{TicketTypes: []models.TicketType{vip}}
+	single := &models.Event{AvailableTickets: 7}
+	unknown := primitive.NewObjectID()
+
+	id, available, reason := resolveTicketType(tiered, &vip.ID)
+	if id != vip.ID || available != 3 || reason != "" {
+		t.Errorf("VIP resolved to %v, %d, %q", id, available, reason)
+	}
+	if _, _, reason := resolveTicketType(tiered, nil); reason == "" {
+		t.Error("tiered event accepted a request without a ticket type")
+	}
+	if _, _, reason := resolveTicketType(tiered, &unknown); reason == "" {
+		t.Error("tiered event accepted an unknown ticket type")
+	}
+
+	id, available, reason = resolveTicketType(single, nil)
+	if !id.IsZero() || available != 7 || reason != "" {
+		t.Errorf("single pool resolved to %v, %d, %q", id, available, reason)
+	}
+	if _, _, reason := resolveTicketType(single, &vip.ID); reason == "" {
+		t.Error("single pool event accepted a ticket type")
+	}
+}
+
+func TestPurchaseLimitReason(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	event := &models.Event{ID: primitive.NewObjectID(), MaxPerOrder: 3, MaxPerUser: 4}
+	userID := primitive.NewObjectID()
+
+	check := func(quantity int) string {
+		t.Helper()
+		reason, err := purchaseLimitReason(ctx, store.Tickets, store.Holds, event, userID, quantity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return reason
+	}
+
+	if reason := check(4); reason != "At most 3 tickets can be booked per order" {
+		t.Errorf("4 seats: %q", reason)
+	}
+	if reason := check(3); reason != "" {
+		t.Errorf("3 seats: %q", reason)
+	}
+
+	// Seats already owned and held count towards the per-user limit
+	if err := store.Tickets.Create(ctx, &models.Ticket{ID: primitive.NewObjectID(), EventID: event.ID, UserID: userID, Status: "active"}); err != nil {
+		t.Fatal(err)
+	}
+	hold := &models.Hold{ID: primitive.NewObjectID(), EventID: event.ID, UserID: userID, Quantity: 2, Status: "active", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := store.Holds.Create(ctx, hold); err != nil {
+		t.Fatal(err)
+	}
+	if reason := check(2); reason != "At most 4 tickets can be booked per user" {
+		t.Errorf("2 more seats: %q", reason)
+	}
+	if reason := check(1); reason != "" {
+		t.Errorf("1 more seat: %q", reason)
+	}
+}
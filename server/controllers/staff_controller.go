@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"server/models"
+	"server/repository"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StaffController struct {
+	staff repository.StaffRepository
+	users repository.UserRepository
+}
+
+func NewStaffController(store *repository.Store) *StaffController {
+	return &StaffController{
+		staff: store.Staff,
+		users: store.Users,
+	}
+}
+
+// AcceptInvite binds a staff invite to the calling account. The account
+// must have the invited email and the token can only be used once.
+func (sc *StaffController) AcceptInvite(c *gin.Context) {
+	var req models.AcceptStaffInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	// The email is not carried by the token
+	user, err := sc.users.FindByID(context.Background(), userObjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	assignment, err := sc.staff.Accept(context.Background(), hashInviteToken(req.Token), strings.ToLower(user.Email), user.ID, time.Now())
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invite not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept invite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+// newInviteToken returns a random single-use token for a staff invite.
+func newInviteToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// hashInviteToken is what gets stored for an invite token, so a leaked
+// database does not leak usable invites.
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
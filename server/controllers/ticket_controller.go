@@ -2,19 +2,87 @@ package controllers
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
 	"net/http"
-	"server/database"
+	"server/config"
+	"server/documents"
 	"server/models"
+	"server/payments"
+	"server/repository"
 	"server/utils"
+	"server/waitlist"
+	"server/wallet"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-type TicketController struct{}
+type TicketController struct {
+	events   repository.EventRepository
+	tickets  repository.TicketRepository
+	orders   repository.OrderRepository
+	holds    repository.HoldRepository
+	refunds  repository.RefundRepository
+	users    repository.UserRepository
+	scans    repository.ScanRepository
+	staff    repository.StaffRepository
+	listings repository.ListingRepository
+	promos   repository.PromoCodeRepository
+
+	payments *payments.Service
+	waitlist *waitlist.Service
+	holdTTL  time.Duration
+
+	// Wallet signers are nil when that wallet is not configured
+	appleWallet  *wallet.AppleSigner
+	googleWallet *wallet.GoogleSigner
+}
+
+func NewTicketController(store *repository.Store, paymentService *payments.Service, offers *waitlist.Service) *TicketController {
+	cfg := config.Load()
+
+	tc := &TicketController{
+		events:   store.Events,
+		tickets:  store.Tickets,
+		orders:   store.Orders,
+		holds:    store.Holds,
+		refunds:  store.Refunds,
+		users:    store.Users,
+		scans:    store.Scans,
+		staff:    store.Staff,
+		listings: store.Listings,
+		promos:   store.Promos,
+		payments: paymentService,
+		waitlist: offers,
+		holdTTL:  cfg.HoldTTL,
+	}
+
+	// Broken wallet credentials only disable pass export
+	var err error
+	if tc.appleWallet, err = wallet.NewAppleSigner(cfg); err != nil && err != wallet.ErrNotConfigured {
+		log.Printf("Apple Wallet passes disabled: %v", err)
+	}
+	if tc.googleWallet, err = wallet.NewGoogleSigner(cfg); err != nil && err != wallet.ErrNotConfigured {
+		log.Printf("Google Wallet passes disabled: %v", err)
+	}
+
+	return tc
+}
+
+// now is the waitlist service's clock, so bookings and the offers made for
+// seats they give back keep the same time.
+func (tc *TicketController) now() time.Time {
+	return tc.waitlist.Now()
+}
 
 func (tc *TicketController) BookTicket(c *gin.Context) {
 	eventID := c.Param("eventId")
@@ -24,125 +92,307 @@ func (tc *TicketController) BookTicket(c *gin.Context) {
 		return
 	}
 
+	// The body is optional; an empty one books a single ticket
+	var req models.BookTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Get user ID from context
 	userID, _ := c.Get("userID")
 	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
 
-	// Check if event exists and has available tickets
-	eventsCollection := database.GetCollection("events")
-	var event models.Event
-	err = eventsCollection.FindOne(context.Background(), bson.M{"_id": eventObjectID}).Decode(&event)
+	selection, ok := tc.selectSeats(c, eventObjectID, userObjectID, req)
+	if !ok {
+		return
+	}
+
+	if !tc.applyPromoCode(c, selection, req.PromoCode) {
+		return
+	}
+
+	// Reserve the seats before creating any tickets so concurrent
+	// bookings can never oversell the event
+	if !tc.reserveSeats(c, selection) {
+		tc.releasePromoCode(selection)
+		return
+	}
+
+	order, tickets, err := tc.issueTickets(selection, userObjectID, primitive.NilObjectID)
+	if err != nil {
+		// Return the reserved seats
+		tc.events.ReleaseTickets(context.Background(), eventObjectID, selection.ticketTypeID, selection.quantity)
+		tc.releasePromoCode(selection)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to book ticket"})
+		return
+	}
+
+	tc.chargeOrder(c, order, tickets, req.PaymentToken)
+}
+
+// HoldSeats takes seats out of inventory for the configured hold TTL so
+// the buyer can complete checkout without losing them.
+func (tc *TicketController) HoldSeats(c *gin.Context) {
+	eventID := c.Param("eventId")
+	eventObjectID, err := primitive.ObjectIDFromHex(eventID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req models.BookTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	selection, ok := tc.selectSeats(c, eventObjectID, userObjectID, req)
+	if !ok {
+		return
+	}
+
+	if !tc.reserveSeats(c, selection) {
+		return
+	}
+
+	now := tc.now()
+	hold := models.Hold{
+		EventID:      eventObjectID,
+		UserID:       userObjectID,
+		TicketTypeID: selection.ticketTypeID,
+		Quantity:     selection.quantity,
+		Status:       "active",
+		ExpiresAt:    now.Add(tc.holdTTL),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := tc.holds.Create(context.Background(), &hold); err != nil {
+		tc.events.ReleaseTickets(context.Background(), eventObjectID, selection.ticketTypeID, selection.quantity)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hold seats"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+// CheckoutHold turns an unexpired hold into an order.
+func (tc *TicketController) CheckoutHold(c *gin.Context) {
+	var req models.CheckoutHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hold, ok := tc.findOwnedHold(c)
+	if !ok {
+		return
+	}
+
+	if hold.Status != "active" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Hold is " + hold.Status})
+		return
+	}
+
+	if !tc.now().Before(hold.ExpiresAt) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Hold has expired"})
+		return
+	}
+
+	event, err := tc.events.FindByID(context.Background(), hold.EventID)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
-			return
-		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
-	if event.AvailableTickets <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No tickets available"})
+	// The held seats stay held until the hold is released or expires
+	if reason := event.SalesClosedReason(tc.now()); reason != "" {
+		c.JSON(http.StatusConflict, gin.H{"error": reason})
 		return
 	}
 
-	// Generate QR code string
-	qrCode := utils.GenerateQRString()
+	selection := newSeatSelection(event, hold.TicketTypeID, hold.Quantity)
+	if !tc.applyPromoCode(c, selection, req.PromoCode) {
+		return
+	}
 
-	// Create ticket
-	ticket := models.Ticket{
-		EventID:   eventObjectID,
-		UserID:    userObjectID,
-		QRCode:    qrCode,
-		Status:    "active",
-		Price:     event.Price,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	// Claim the hold first so the expirer cannot release the same seats
+	if err := tc.holds.TransitionStatus(context.Background(), hold.ID, "active", "converted"); err != nil {
+		tc.releasePromoCode(selection)
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Hold has expired"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check out hold"})
+		return
 	}
 
-	ticketsCollection := database.GetCollection("tickets")
-	result, err := ticketsCollection.InsertOne(context.Background(), ticket)
+	order, tickets, err := tc.issueTickets(selection, hold.UserID, hold.ID)
 	if err != nil {
+		tc.holds.TransitionStatus(context.Background(), hold.ID, "converted", "released")
+		tc.events.ReleaseTickets(context.Background(), hold.EventID, hold.TicketTypeID, hold.Quantity)
+		tc.releasePromoCode(selection)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to book ticket"})
 		return
 	}
 
-	// Update available tickets count
-	_, err = eventsCollection.UpdateOne(
-		context.Background(),
-		bson.M{"_id": eventObjectID},
-		bson.M{"$inc": bson.M{"available_tickets": -1}},
-	)
-	if err != nil {
-		// Rollback ticket creation
-		ticketsCollection.DeleteOne(context.Background(), bson.M{"_id": result.InsertedID})
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+	if err := tc.waitlist.CloseOffer(context.Background(), hold.ID, "booked"); err != nil {
+		log.Printf("Error closing waitlist offer for hold %s: %v", hold.ID.Hex(), err)
+	}
+
+	tc.chargeOrder(c, order, tickets, req.PaymentToken)
+}
+
+// ReleaseHold gives held seats back before the hold expires.
+func (tc *TicketController) ReleaseHold(c *gin.Context) {
+	hold, ok := tc.findOwnedHold(c)
+	if !ok {
+		return
+	}
+
+	if err := tc.holds.TransitionStatus(context.Background(), hold.ID, "active", "released"); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Hold is no longer active"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to release hold"})
+		return
+	}
+
+	if err := tc.events.ReleaseTickets(context.Background(), hold.EventID, hold.TicketTypeID, hold.Quantity); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to release seats"})
 		return
 	}
 
-	ticket.ID = result.InsertedID.(primitive.ObjectID)
-	c.JSON(http.StatusCreated, ticket)
+	if err := tc.waitlist.CloseOffer(context.Background(), hold.ID, "declined"); err != nil {
+		log.Printf("Error closing waitlist offer for hold %s: %v", hold.ID.Hex(), err)
+	}
+	tc.offerToWaitlist(hold.EventID, hold.TicketTypeID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Hold released successfully"})
 }
 
 func (tc *TicketController) GetMyTickets(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
 
-	ticketsCollection := database.GetCollection("tickets")
+	tickets, err := tc.tickets.ListByUser(context.Background(), userObjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tickets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tickets": tickets})
+}
 
-	// Aggregation pipeline to join tickets with events
-	pipeline := []bson.M{
-		{"$match": bson.M{"user_id": userObjectID}},
-		{"$lookup": bson.M{
-			"from":         "events",
-			"localField":   "event_id",
-			"foreignField": "_id",
-			"as":           "event",
-		}},
-		{"$unwind": "$event"},
-		{"$sort": bson.M{"created_at": -1}},
+func (tc *TicketController) CancelTicket(c *gin.Context) {
+	ticket, ok := tc.findOwnedTicket(c)
+	if !ok {
+		return
 	}
 
-	cursor, err := ticketsCollection.Aggregate(context.Background(), pipeline)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tickets"})
+	if ticket.Status == "used" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ticket already used"})
 		return
 	}
-	defer cursor.Close(context.Background())
 
-	var tickets []bson.M
-	if err := cursor.All(context.Background(), &tickets); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode tickets"})
+	if ticket.Status == "cancelled" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ticket is already cancelled"})
 		return
 	}
 
-	// Transform the result to match our desired structure
-	var result []models.TicketWithEvent
-	for _, ticket := range tickets {
-		eventData := ticket["event"].(bson.M)
+	if ticket.Status == "pending" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ticket payment is still being processed"})
+		return
+	}
+
+	// Tickets that allow re-entry stay active after their first scan
+	if ticket.ScanCount > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ticket has already been scanned"})
+		return
+	}
+
+	// The refund would go to the original buyer's payment, not the holder
+	if len(ticket.Transfers) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transferred tickets cannot be cancelled"})
+		return
+	}
+
+	if _, err := tc.listings.FindOpenByTicket(context.Background(), ticket.ID); err != repository.ErrNotFound {
+		if err == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ticket is listed for resale"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	event, err := tc.events.FindByID(context.Background(), ticket.EventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	now := tc.now()
+	if now.After(event.CancelDeadline()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cancellation deadline has passed"})
+		return
+	}
 
-		// Convert event data
-		event := models.Event{
-			ID:          eventData["_id"].(primitive.ObjectID),
-			Title:       eventData["title"].(string),
-			Description: eventData["description"].(string),
-			Date:        eventData["date"].(primitive.DateTime).Time(),
-			Location:    eventData["location"].(string),
-			Price:       eventData["price"].(float64),
+	// The conditional status change makes sure only one request can cancel
+	// the ticket and so only one seat is ever returned for it
+	if err := tc.tickets.TransitionStatus(context.Background(), ticket.ID, "active", "cancelled"); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Ticket can no longer be cancelled"})
+			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel ticket"})
+		return
+	}
 
-		ticketWithEvent := models.TicketWithEvent{
-			ID:        ticket["_id"].(primitive.ObjectID),
-			Event:     event,
-			QRCode:    ticket["qr_code"].(string),
-			Status:    ticket["status"].(string),
-			Price:     ticket["price"].(float64),
-			CreatedAt: ticket["created_at"].(primitive.DateTime).Time(),
+	// The refund is recorded before the seat goes back on sale so that a
+	// failure here can still hand the ticket back to its holder
+	refund := models.Refund{
+		TicketID:  ticket.ID,
+		OrderID:   ticket.OrderID,
+		EventID:   ticket.EventID,
+		UserID:    ticket.UserID,
+		Amount:    ticket.Price,
+		Reason:    "cancelled by ticket holder",
+		Status:    "pending",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := tc.refunds.Create(context.Background(), &refund); err != nil {
+		if err := tc.tickets.TransitionStatus(context.Background(), ticket.ID, "cancelled", "active"); err != nil {
+			log.Printf("Error restoring cancelled ticket %s: %v", ticket.ID.Hex(), err)
 		}
-		result = append(result, ticketWithEvent)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record refund"})
+		return
+	}
+
+	// The cancellation stands once its refund is on record; a seat that
+	// could not be returned stays off sale rather than being sold twice
+	if err := tc.events.ReleaseTickets(context.Background(), ticket.EventID, ticket.TicketTypeID, 1); err != nil {
+		log.Printf("Error releasing seat of cancelled ticket %s: %v", ticket.ID.Hex(), err)
+	} else {
+		tc.offerToWaitlist(ticket.EventID, ticket.TicketTypeID)
 	}
 
-	c.JSON(http.StatusOK, gin.H{"tickets": result})
+	if err := tc.payments.Refund(context.Background(), &refund); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process refund"})
+		return
+	}
+
+	ticket.Status = "cancelled"
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ticket cancelled successfully",
+		"ticket":  ticket,
+		"refund":  refund,
+	})
 }
 
 func (tc *TicketController) ValidateTicket(c *gin.Context) {
@@ -152,12 +402,20 @@ func (tc *TicketController) ValidateTicket(c *gin.Context) {
 		return
 	}
 
+	// Signed codes are checked before any database lookup so forged ones
+	// are turned away cheaply. Legacy unsigned codes can only be checked
+	// against the database.
+	if utils.IsSignedQR(req.QRCode) {
+		if _, err := utils.ParseQRString(req.QRCode); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid QR code"})
+			return
+		}
+	}
+
 	// Find ticket by QR code
-	ticketsCollection := database.GetCollection("tickets")
-	var ticket models.Ticket
-	err := ticketsCollection.FindOne(context.Background(), bson.M{"qr_code": req.QRCode}).Decode(&ticket)
+	ticket, err := tc.tickets.FindByQRCode(context.Background(), req.QRCode)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
+		if err == repository.ErrNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Invalid QR code"})
 			return
 		}
@@ -165,39 +423,965 @@ func (tc *TicketController) ValidateTicket(c *gin.Context) {
 		return
 	}
 
-	// Check if ticket is already used
-	if ticket.Status == "used" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Ticket already used"})
+	direction, ok := scanDirection(c, req.Direction)
+	if !ok {
 		return
 	}
 
-	if ticket.Status == "cancelled" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Ticket is cancelled"})
+	// Get scanner ID from context
+	scannerID, _ := c.Get("userID")
+	scannerObjectID, _ := primitive.ObjectIDFromHex(scannerID.(string))
+
+	event, err := tc.events.FindByID(context.Background(), ticket.EventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Only the event's own organizer and the staff they assigned may admit
+	// its ticket holders
+	allowed, err := tc.canValidate(scannerObjectID, event)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to validate tickets for this event"})
+		return
+	}
+
+	// Expiry follows the event's current schedule so postponed or extended
+	// events keep admitting the codes issued before the change
+	if tc.now().After(utils.QRExpiry(event.EndsAt())) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "QR code has expired"})
 		return
 	}
 
-	// Mark ticket as used
-	_, err = ticketsCollection.UpdateOne(
-		context.Background(),
-		bson.M{"_id": ticket.ID},
-		bson.M{"$set": bson.M{"status": "used", "updated_at": time.Now()}},
-	)
+	scan := models.Scan{
+		TicketID:  ticket.ID,
+		EventID:   ticket.EventID,
+		ScannedBy: scannerObjectID,
+		Gate:      req.Gate,
+		Direction: direction,
+		ScannedAt: tc.now(),
+	}
+
+	ticket, rejection, err := tc.admitTicket(ticket, event, models.TicketScan{
+		Direction: direction,
+		Gate:      req.Gate,
+		ScannedBy: scannerObjectID,
+		ScannedAt: scan.ScannedAt,
+	})
 	if err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Ticket is being scanned elsewhere, please try again"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate ticket"})
 		return
 	}
 
-	// Get event details
-	eventsCollection := database.GetCollection("events")
-	var event models.Event
-	eventsCollection.FindOne(context.Background(), bson.M{"_id": ticket.EventID}).Decode(&event)
+	if rejection != nil {
+		if !rejection.duplicate {
+			scan.Result = "rejected"
+			tc.recordScan(&scan)
+			c.JSON(rejection.status, gin.H{"error": rejection.message})
+			return
+		}
+		// Tell the scanner when and where the holder was last let in
+		scan.Result = "duplicate"
+		tc.recordScan(&scan)
+		c.JSON(rejection.status, gin.H{
+			"error":     rejection.message,
+			"used_at":   ticket.UsedAt,
+			"used_gate": ticket.UsedGate,
+		})
+		return
+	}
+
+	scan.Result = "accepted"
+	tc.recordScan(&scan)
 
+	message := "Ticket validated successfully"
+	if direction == "out" {
+		message = "Ticket holder checked out"
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Ticket validated successfully",
+		"message": message,
 		"ticket": gin.H{
-			"id":      ticket.ID,
-			"event":   event.Title,
-			"user_id": ticket.UserID,
+			"id":                 ticket.ID,
+			"event":              event.Title,
+			"user_id":            ticket.UserID,
+			"scan_count":         ticket.ScanCount,
+			"admissions_allowed": event.AdmissionsAllowed(),
 		},
+		"scan": scan,
+	})
+}
+
+// admitTicket applies the scan to the ticket and saves it. A scan recorded
+// concurrently by another scanner makes the save fail, in which case the
+// ticket is reloaded and the scan judged again against its new state. The
+// returned ticket is the latest state known.
+func (tc *TicketController) admitTicket(ticket *models.Ticket, event *models.Event, scan models.TicketScan) (*models.Ticket, *scanRejection, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		updated := *ticket
+		updated.Scans = append([]models.TicketScan(nil), ticket.Scans...)
+		if rejection := applyScan(&updated, event, scan); rejection != nil {
+			return ticket, rejection, nil
+		}
+
+		err := tc.tickets.RecordScan(context.Background(), &updated, ticket.Status, scan)
+		if err == nil {
+			return &updated, nil, nil
+		}
+		if err != repository.ErrConflict {
+			return nil, nil, err
+		}
+
+		if ticket, err = tc.tickets.FindByID(context.Background(), ticket.ID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return nil, nil, repository.ErrConflict
+}
+
+// maxOfflineScans caps the size of one offline scan upload.
+const maxOfflineScans = 5000
+
+// GetScanManifest exports the tickets that may currently be admitted to the
+// event so scanners can keep validating without connectivity. The body is
+// signed with the QR key; the signature travels in X-Manifest-Signature.
+func (tc *TicketController) GetScanManifest(c *gin.Context) {
+	event, _, ok := tc.findScannableEvent(c)
+	if !ok {
+		return
+	}
+
+	tickets, err := tc.tickets.ListByEvent(context.Background(), event.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tickets"})
+		return
+	}
+
+	manifest := models.ScanManifest{
+		EventID:         event.ID,
+		MaxScans:        event.AdmissionsAllowed(),
+		ScanLimitPerDay: event.ScanLimitPerDay,
+		Timezone:        event.Timezone,
+		ExpiresAt:       utils.QRExpiry(event.EndsAt()).UTC(),
+		GeneratedAt:     tc.now().UTC(),
+		Tickets:         []models.ManifestTicket{},
+	}
+	for _, ticket := range tickets {
+		if ticket.Status != "active" {
+			continue
+		}
+		manifest.Tickets = append(manifest.Tickets, models.ManifestTicket{
+			TicketID:   ticket.ID,
+			QRCode:     ticket.QRCode,
+			TicketType: ticket.TicketType,
+			ScanCount:  ticket.ScanCount,
+			CheckedIn:  ticket.CheckedIn,
+		})
+	}
+
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build manifest"})
+		return
+	}
+
+	c.Header("X-Manifest-Signature", utils.SignManifest(body))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/json", body)
+}
+
+// UploadOfflineScans reconciles scans a device made while offline against
+// the tickets' current state. Scans are applied oldest first, so the
+// earliest admission wins and later ones are reported as duplicates.
+// Uploading the same batch again is safe; scans already applied come back
+// as "synced".
+func (tc *TicketController) UploadOfflineScans(c *gin.Context) {
+	event, scannerID, ok := tc.findScannableEvent(c)
+	if !ok {
+		return
+	}
+
+	var req models.OfflineScanBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(req.Scans) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No scans to upload"})
+		return
+	}
+	if len(req.Scans) > maxOfflineScans {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d scans can be uploaded at once", maxOfflineScans)})
+		return
+	}
+
+	scans := append([]models.OfflineScan(nil), req.Scans...)
+	sort.SliceStable(scans, func(i, j int) bool { return scans[i].ScannedAt.Before(scans[j].ScannedAt) })
+
+	results := make([]models.OfflineScanResult, 0, len(scans))
+	summary := map[string]int{"accepted": 0, "duplicate": 0, "conflict": 0, "invalid": 0, "synced": 0}
+	for _, scan := range scans {
+		result, err := tc.reconcileOfflineScan(event, scannerID, scan)
+		if err != nil {
+			// Everything before this scan has been applied; uploading the
+			// batch again picks up where this one stopped
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile scans"})
+			return
+		}
+		results = append(results, result)
+		summary[result.Result]++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"summary": summary,
+		"results": results,
+	})
+}
+
+func (tc *TicketController) reconcileOfflineScan(event *models.Event, scannerID primitive.ObjectID, offlineScan models.OfflineScan) (models.OfflineScanResult, error) {
+	result := models.OfflineScanResult{OfflineScan: offlineScan, Result: "invalid"}
+	if offlineScan.QRCode == "" || offlineScan.DeviceID == "" || offlineScan.ScannedAt.IsZero() {
+		result.Reason = "qr_code, device_id and scanned_at are required"
+		return result, nil
+	}
+	if offlineScan.Direction != "" && offlineScan.Direction != "in" && offlineScan.Direction != "out" {
+		result.Reason = "Direction must be 'in' or 'out'"
+		return result, nil
+	}
+
+	// Mongo keeps milliseconds, so compare at that precision
+	scannedAt := offlineScan.ScannedAt.UTC().Truncate(time.Millisecond)
+
+	if utils.IsSignedQR(offlineScan.QRCode) {
+		if _, err := utils.ParseQRString(offlineScan.QRCode); err != nil {
+			result.Reason = "Invalid QR code"
+			return result, nil
+		}
+	}
+
+	// Expiry is judged at the time of the scan, not of the upload
+	if scannedAt.After(utils.QRExpiry(event.EndsAt())) {
+		result.Reason = "QR code had expired"
+		return result, nil
+	}
+
+	ticket, err := tc.tickets.FindByQRCode(context.Background(), offlineScan.QRCode)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			result.Reason = "Invalid QR code"
+			return result, nil
+		}
+		return result, err
+	}
+	if ticket.EventID != event.ID {
+		result.Reason = "Ticket is for another event"
+		return result, nil
+	}
+	result.TicketID = ticket.ID
+
+	synced, err := tc.scans.ExistsFromDevice(context.Background(), ticket.ID, offlineScan.DeviceID, scannedAt)
+	if err != nil {
+		return result, err
+	}
+	if synced {
+		result.Result = "synced"
+		return result, nil
+	}
+
+	direction := offlineScan.Direction
+	if direction == "" {
+		direction = "in"
+	}
+	scan := models.Scan{
+		TicketID:  ticket.ID,
+		EventID:   ticket.EventID,
+		ScannedBy: scannerID,
+		Gate:      offlineScan.Gate,
+		Direction: direction,
+		DeviceID:  offlineScan.DeviceID,
+		ScannedAt: scannedAt,
+	}
+
+	ticket, rejection, err := tc.admitTicket(ticket, event, models.TicketScan{
+		Direction: direction,
+		Gate:      offlineScan.Gate,
+		DeviceID:  offlineScan.DeviceID,
+		ScannedBy: scannerID,
+		ScannedAt: scannedAt,
 	})
+	if err != nil {
+		return result, err
+	}
+
+	switch {
+	case rejection == nil:
+		result.Result = "accepted"
+		scan.Result = "accepted"
+	case rejection.duplicate:
+		// The holder was let in more than once
+		result.Result = "duplicate"
+		result.Reason = rejection.message
+		result.UsedAt = ticket.UsedAt
+		result.UsedGate = ticket.UsedGate
+		scan.Result = "duplicate"
+	default:
+		// The device let in a ticket that is not valid for entry
+		result.Result = "conflict"
+		result.Reason = rejection.message
+		scan.Result = "rejected"
+	}
+
+	// A concurrent upload of the same batch recorded the scan first
+	if err := tc.scans.Create(context.Background(), &scan); err != nil {
+		if err == repository.ErrConflict {
+			return models.OfflineScanResult{OfflineScan: offlineScan, TicketID: ticket.ID, Result: "synced"}, nil
+		}
+		return result, err
+	}
+	return result, nil
+}
+
+// findScannableEvent loads the event named by the eventId parameter and
+// checks the caller may validate its tickets.
+func (tc *TicketController) findScannableEvent(c *gin.Context) (*models.Event, primitive.ObjectID, bool) {
+	eventID, err := primitive.ObjectIDFromHex(c.Param("eventId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return nil, primitive.NilObjectID, false
+	}
+
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	event, err := tc.events.FindByID(context.Background(), eventID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return nil, primitive.NilObjectID, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, primitive.NilObjectID, false
+	}
+
+	allowed, err := tc.canValidate(userObjectID, event)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, primitive.NilObjectID, false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to validate tickets for this event"})
+		return nil, primitive.NilObjectID, false
+	}
+
+	return event, userObjectID, true
+}
+
+// canValidate reports whether the user may validate tickets for the event.
+func (tc *TicketController) canValidate(userID primitive.ObjectID, event *models.Event) (bool, error) {
+	if event.OrganizerID == userID {
+		return true, nil
+	}
+	return tc.staff.IsAssigned(context.Background(), event.ID, userID)
+}
+
+// scanDirection defaults an empty direction to "in" and rejects anything
+// other than "in" or "out".
+func scanDirection(c *gin.Context, direction string) (string, bool) {
+	switch direction {
+	case "":
+		return "in", true
+	case "in", "out":
+		return direction, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Direction must be 'in' or 'out'"})
+	return "", false
+}
+
+// recordScan keeps the audit trail of door scans. The ticket's status is the
+// source of truth for admission, so a failed write does not fail the scan.
+func (tc *TicketController) recordScan(scan *models.Scan) {
+	if err := tc.scans.Create(context.Background(), scan); err != nil {
+		log.Printf("Error recording scan of ticket %s: %v", scan.TicketID.Hex(), err)
+	}
+}
+
+// offerToWaitlist passes released seats on to waitlisted users. The seats
+// are already back on sale, so a failure here is only logged.
+func (tc *TicketController) offerToWaitlist(eventID, ticketTypeID primitive.ObjectID) {
+	if _, err := tc.waitlist.OfferSeats(context.Background(), eventID, ticketTypeID); err != nil {
+		log.Printf("Error offering seats of event %s to the waitlist: %v", eventID.Hex(), err)
+	}
+}
+
+// seatSelection is a validated request for seats of a single event and
+// ticket type.
+type seatSelection struct {
+	event        *models.Event
+	ticketTypeID primitive.ObjectID
+	ticketType   string
+	price        float64
+	quantity     int
+	// promo is the redeemed promo code, if any
+	promo *models.PromoCode
+}
+
+func newSeatSelection(event *models.Event, ticketTypeID primitive.ObjectID, quantity int) *seatSelection {
+	selection := &seatSelection{
+		event:    event,
+		price:    event.Price,
+		quantity: quantity,
+	}
+	if ticketType := event.FindTicketType(ticketTypeID); ticketType != nil {
+		selection.ticketTypeID = ticketType.ID
+		selection.ticketType = ticketType.Name
+		selection.price = ticketType.Price
+	}
+	return selection
+}
+
+// selectSeats loads the event and checks the requested seats against its
+// inventory and purchase limits, writing the error response itself when
+// the request cannot be honored.
+func (tc *TicketController) selectSeats(c *gin.Context, eventID, userID primitive.ObjectID, req models.BookTicketRequest) (*seatSelection, bool) {
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be greater than 0"})
+		return nil, false
+	}
+
+	// Check if event exists and has available tickets
+	event, err := tc.events.FindByID(context.Background(), eventID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+
+	if reason := event.SalesClosedReason(tc.now()); reason != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": reason})
+		return nil, false
+	}
+
+	ticketTypeID, available, reason := resolveTicketType(event, req.TicketTypeID)
+	if reason != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": reason})
+		return nil, false
+	}
+
+	if available < quantity {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No tickets available"})
+		return nil, false
+	}
+
+	// Enforce the organizer's purchase limits
+	reason, err = purchaseLimitReason(context.Background(), tc.tickets, tc.holds, event, userID, quantity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+	if reason != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": reason})
+		return nil, false
+	}
+
+	return newSeatSelection(event, ticketTypeID, quantity), true
+}
+
+// applyPromoCode checks a promo code against the selection and counts its
+// uses, writing the error response itself when the code cannot be used. An
+// empty code leaves the selection at full price.
+func (tc *TicketController) applyPromoCode(c *gin.Context, selection *seatSelection, code string) bool {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return true
+	}
+
+	promo, err := tc.promos.FindByCode(context.Background(), selection.event.ID, code)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promo code"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return false
+	}
+
+	if !promo.ValidAt(tc.now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Promo code is not valid at this time"})
+		return false
+	}
+
+	if !promo.AppliesTo(selection.ticketTypeID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Promo code does not apply to this ticket type"})
+		return false
+	}
+
+	// The conditional increment makes sure the usage limit holds under
+	// concurrent bookings
+	if err := tc.promos.Redeem(context.Background(), promo.ID, selection.quantity); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Promo code usage limit reached"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeem promo code"})
+		return false
+	}
+
+	selection.promo = promo
+	return true
+}
+
+// releasePromoCode gives back the uses counted for a booking that did not
+// go through.
+func (tc *TicketController) releasePromoCode(selection *seatSelection) {
+	if selection.promo == nil {
+		return
+	}
+	if err := tc.promos.Release(context.Background(), selection.promo.ID, selection.quantity); err != nil {
+		log.Printf("Error releasing promo code %s: %v", selection.promo.Code, err)
+	}
+}
+
+// reserveSeats atomically takes the selected seats out of inventory.
+func (tc *TicketController) reserveSeats(c *gin.Context, selection *seatSelection) bool {
+	err := tc.events.ReserveTickets(context.Background(), selection.event.ID, selection.ticketTypeID, selection.quantity)
+	if err != nil {
+		if err == repository.ErrSoldOut {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No tickets available"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event"})
+		return false
+	}
+	return true
+}
+
+// issueTickets creates a pending order and one pending ticket per seat for
+// seats that have already been reserved. Partially written tickets are
+// removed on failure, but returning the seats is left to the caller.
+func (tc *TicketController) issueTickets(selection *seatSelection, userID, holdID primitive.ObjectID) (*models.Order, []models.Ticket, error) {
+	now := tc.now()
+	order := models.Order{
+		ID:           primitive.NewObjectID(),
+		EventID:      selection.event.ID,
+		UserID:       userID,
+		TicketTypeID: selection.ticketTypeID,
+		HoldID:       holdID,
+		Quantity:     selection.quantity,
+		Status:       "pending",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	price := selection.price
+	if selection.promo != nil {
+		price = selection.promo.Apply(selection.price)
+		order.PromoCodeID = selection.promo.ID
+	}
+
+	// Create one ticket per seat, each with its own QR code
+	tickets := make([]models.Ticket, selection.quantity)
+	for i := range tickets {
+		ticketID := primitive.NewObjectID()
+		tickets[i] = models.Ticket{
+			ID:           ticketID,
+			EventID:      selection.event.ID,
+			UserID:       userID,
+			OrderID:      order.ID,
+			TicketTypeID: selection.ticketTypeID,
+			TicketType:   selection.ticketType,
+			QRCode:       newTicketQR(ticketID, selection.event),
+			Status:       "pending",
+			Price:        price,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if selection.promo != nil {
+			tickets[i].PromoCode = selection.promo.Code
+			tickets[i].Discount = math.Round((selection.price-price)*100) / 100
+		}
+		order.TicketIDs = append(order.TicketIDs, tickets[i].ID)
+		order.TotalPrice += tickets[i].Price
+	}
+
+	if err := tc.tickets.CreateMany(context.Background(), tickets); err != nil {
+		tc.tickets.DeleteByOrder(context.Background(), order.ID)
+		return nil, nil, err
+	}
+
+	if err := tc.orders.Create(context.Background(), &order); err != nil {
+		tc.tickets.DeleteByOrder(context.Background(), order.ID)
+		return nil, nil, err
+	}
+
+	return &order, tickets, nil
+}
+
+// newTicketQR signs a fresh QR code for a ticket of the event.
+func newTicketQR(ticketID primitive.ObjectID, event *models.Event) string {
+	return utils.NewTicketQR(ticketID, event.ID, event.EndsAt())
+}
+
+// GetQRPublicKey publishes the key that verifies ticket QR codes so venue
+// scanners can check them without connectivity.
+func (tc *TicketController) GetQRPublicKey(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"algorithm":  "Ed25519",
+		"public_key": base64.StdEncoding.EncodeToString(utils.QRPublicKey()),
+	})
+}
+
+// GetTicketQRPNG renders the ticket's QR code as a PNG for embedding in
+// emails and documents. The optional size query sets the width in pixels.
+func (tc *TicketController) GetTicketQRPNG(c *gin.Context) {
+	ticket, ok := tc.findRenderableTicket(c)
+	if !ok {
+		return
+	}
+
+	size := 256
+	if sizeParam := c.Query("size"); sizeParam != "" {
+		parsed, err := strconv.Atoi(sizeParam)
+		if err != nil || parsed < 64 || parsed > 2048 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Size must be between 64 and 2048"})
+			return
+		}
+		size = parsed
+	}
+
+	png, err := utils.RenderQRPNG(ticket.QRCode, size)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// GetTicketQRSVG renders the ticket's QR code as an SVG.
+func (tc *TicketController) GetTicketQRSVG(c *gin.Context) {
+	ticket, ok := tc.findRenderableTicket(c)
+	if !ok {
+		return
+	}
+
+	svg, err := utils.RenderQRSVG(ticket.QRCode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "image/svg+xml", svg)
+}
+
+// GetTicketPDF returns a printable PDF of a single ticket.
+func (tc *TicketController) GetTicketPDF(c *gin.Context) {
+	ticket, ok := tc.findRenderableTicket(c)
+	if !ok {
+		return
+	}
+
+	tc.renderTicketsPDF(c, ticket.EventID, ticket.UserID, []models.Ticket{*ticket}, "ticket-"+ticket.ID.Hex()+".pdf")
+}
+
+// GetOrderPDF returns every valid ticket of an order in one PDF, a page per
+// ticket.
+func (tc *TicketController) GetOrderPDF(c *gin.Context) {
+	orderID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	order, err := tc.orders.FindByID(context.Background(), orderID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if order.UserID != userObjectID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	if order.Status != "paid" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order has not been paid"})
+		return
+	}
+
+	// Cancelled tickets are left out; the ones still valid may have changed
+	// hands since the order was placed, so ownership is checked per ticket
+	var tickets []models.Ticket
+	for _, ticketID := range order.TicketIDs {
+		ticket, err := tc.tickets.FindByID(context.Background(), ticketID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if ticket.UserID == userObjectID && (ticket.Status == "active" || ticket.Status == "used") {
+			tickets = append(tickets, *ticket)
+		}
+	}
+
+	if len(tickets) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order has no valid tickets"})
+		return
+	}
+
+	tc.renderTicketsPDF(c, order.EventID, userObjectID, tickets, "order-"+order.ID.Hex()+".pdf")
+}
+
+func (tc *TicketController) renderTicketsPDF(c *gin.Context, eventID, userID primitive.ObjectID, tickets []models.Ticket, filename string) {
+	event, err := tc.events.FindByID(context.Background(), eventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	holder, err := tc.users.FindByID(context.Background(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	document := documents.TicketPDF{Event: event, Holder: holder, Currency: config.Load().Currency}
+	pdf, err := document.Render(tickets)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// GetAppleWalletPass returns the ticket as a signed .pkpass bundle.
+func (tc *TicketController) GetAppleWalletPass(c *gin.Context) {
+	if tc.appleWallet == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Apple Wallet passes are not available"})
+		return
+	}
+
+	pass, ok := tc.loadWalletPass(c)
+	if !ok {
+		return
+	}
+
+	bundle, err := tc.appleWallet.Bundle(*pass)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate pass"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="ticket-`+pass.Ticket.ID.Hex()+`.pkpass"`)
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/vnd.apple.pkpass", bundle)
+}
+
+// GetGoogleWalletLink returns a link that saves the ticket to Google Wallet.
+func (tc *TicketController) GetGoogleWalletLink(c *gin.Context) {
+	if tc.googleWallet == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Google Wallet passes are not available"})
+		return
+	}
+
+	pass, ok := tc.loadWalletPass(c)
+	if !ok {
+		return
+	}
+
+	saveURL, err := tc.googleWallet.SaveURL(*pass)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate pass"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"save_url": saveURL})
+}
+
+func (tc *TicketController) loadWalletPass(c *gin.Context) (*wallet.Pass, bool) {
+	ticket, ok := tc.findRenderableTicket(c)
+	if !ok {
+		return nil, false
+	}
+
+	event, err := tc.events.FindByID(context.Background(), ticket.EventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+
+	holder, err := tc.users.FindByID(context.Background(), ticket.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+
+	return &wallet.Pass{Ticket: ticket, Event: event, Holder: holder}, true
+}
+
+// findOwnedTicket loads the ticket named by the id parameter and checks it
+// belongs to the calling user, writing the error response itself when it
+// does not. Anyone but the owner is told the ticket does not exist.
+func (tc *TicketController) findOwnedTicket(c *gin.Context) (*models.Ticket, bool) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return nil, false
+	}
+
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	ticket, err := tc.tickets.FindByID(context.Background(), ticketID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+
+	if ticket.UserID != userObjectID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		return nil, false
+	}
+
+	return ticket, true
+}
+
+// findRenderableTicket is findOwnedTicket for handlers that hand out the QR
+// code, which is only done once the ticket is paid for and still valid.
+func (tc *TicketController) findRenderableTicket(c *gin.Context) (*models.Ticket, bool) {
+	ticket, ok := tc.findOwnedTicket(c)
+	if !ok {
+		return nil, false
+	}
+
+	switch ticket.Status {
+	case "pending":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ticket payment is still being processed"})
+		return nil, false
+	case "cancelled":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ticket is cancelled"})
+		return nil, false
+	}
+
+	return ticket, true
+}
+
+// findOwnedHold loads the hold named in the URL and checks it belongs to the
+// caller, writing the error response itself when it does not.
+func (tc *TicketController) findOwnedHold(c *gin.Context) (*models.Hold, bool) {
+	holdID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hold ID"})
+		return nil, false
+	}
+
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	hold, err := tc.holds.FindByID(context.Background(), holdID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+
+	if hold.UserID != userObjectID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+		return nil, false
+	}
+
+	return hold, true
+}
+
+// chargeOrder takes payment for a freshly issued order and writes the
+// booking response. Declined payments have already released their seats.
+func (tc *TicketController) chargeOrder(c *gin.Context, order *models.Order, tickets []models.Ticket, paymentToken string) {
+	status, err := tc.payments.Charge(context.Background(), order, paymentToken)
+	if err != nil {
+		if err == payments.ErrDeclined {
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": "Payment declined"})
+			return
+		}
+		if status == "failed" {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Payment failed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete booking"})
+		return
+	}
+
+	order.Status = status
+	if status == "paid" {
+		for i := range tickets {
+			tickets[i].Status = "active"
+		}
+	}
+
+	c.JSON(http.StatusCreated, newBookOrderResponse(order, tickets))
+}
+
+func newBookOrderResponse(order *models.Order, tickets []models.Ticket) models.BookOrderResponse {
+	message := "Tickets booked successfully"
+	if order.Status == "pending" {
+		message = "Payment is being processed"
+	}
+
+	response := models.BookOrderResponse{
+		OrderID:    order.ID,
+		EventID:    order.EventID,
+		Quantity:   order.Quantity,
+		TotalPrice: order.TotalPrice,
+		Status:     order.Status,
+		Message:    message,
+		CreatedAt:  order.CreatedAt,
+	}
+	for _, ticket := range tickets {
+		response.Tickets = append(response.Tickets, models.BookTicketResponse{
+			ID:         ticket.ID,
+			EventID:    ticket.EventID,
+			TicketType: ticket.TicketType,
+			QRCode:     ticket.QRCode,
+			Status:     ticket.Status,
+			Price:      ticket.Price,
+			PromoCode:  ticket.PromoCode,
+			Discount:   ticket.Discount,
+			CreatedAt:  ticket.CreatedAt,
+		})
+	}
+	return response
 }
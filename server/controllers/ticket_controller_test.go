@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"context"
+	"server/models"
+	"server/payments"
+	"server/repository"
+	"server/waitlist"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTicketControllerClock(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	offers := waitlist.NewService(store, 30*time.Minute)
+	paymentService := payments.NewService(store, payments.NewFakeProvider("secret", 0), "usd", offers)
+	tc := NewTicketController(store, paymentService, offers)
+
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	offers.Now = func() time.Time { return now }
+
+	event := &models.Event{
+		ID:               primitive.NewObjectID(),
+		Title:            "Concert",
+		Date:             now.Add(30 * 24 * time.Hour),
+		Price:            25,
+		TotalTickets:     2,
+		AvailableTickets: 2,
+		Status:           "published",
+	}
+	if err := store.Events.Create(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+
+	order, tickets, err := tc.issueTickets(newSeatSelection(event, primitive.NilObjectID, 1), primitive.NewObjectID(), primitive.NilObjectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !order.CreatedAt.Equal(now) || !tickets[0].CreatedAt.Equal(now) {
+		t.Errorf("issued at %v and %v, want %v", order.CreatedAt, tickets[0].CreatedAt, now)
+	}
+
+	// Waitlist offers run on the same clock
+	entry := &models.WaitlistEntry{
+		ID:        primitive.NewObjectID(),
+		EventID:   event.ID,
+		UserID:    primitive.NewObjectID(),
+		Quantity:  1,
+		Status:    "waiting",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := store.Waitlist.Create(ctx, entry); err != nil {
+		t.Fatal(err)
+	}
+	tc.offerToWaitlist(event.ID, primitive.NilObjectID)
+	offered, err := store.Waitlist.FindOpenByUser(ctx, event.ID, entry.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(30 * time.Minute); offered.Status != "offered" || !offered.OfferExpiresAt.Equal(want) {
+		t.Errorf("offer %q expires at %v, want %v", offered.Status, offered.OfferExpiresAt, want)
+	}
+}
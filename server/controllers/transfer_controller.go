@@ -0,0 +1,314 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"server/models"
+	"server/repository"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TransferController struct {
+	events    repository.EventRepository
+	tickets   repository.TicketRepository
+	transfers repository.TransferRepository
+	listings  repository.ListingRepository
+	users     repository.UserRepository
+}
+
+func NewTransferController(store *repository.Store) *TransferController {
+	return &TransferController{
+		events:    store.Events,
+		tickets:   store.Tickets,
+		transfers: store.Transfers,
+		listings:  store.Listings,
+		users:     store.Users,
+	}
+}
+
+// CreateTransfer offers one of the caller's tickets to the user with the
+// given email. The ticket stays with the caller until the recipient accepts.
+func (tc *TransferController) CreateTransfer(c *gin.Context) {
+	ticketID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ticket ID"})
+		return
+	}
+
+	var req models.TransferTicketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email is required"})
+		return
+	}
+
+	user, ok := tc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	ticket, err := tc.tickets.FindByID(context.Background(), ticketID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Only the owner may transfer; anyone else is told it does not exist
+	if ticket.UserID != user.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Ticket not found"})
+		return
+	}
+
+	if strings.ToLower(user.Email) == email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot transfer a ticket to yourself"})
+		return
+	}
+
+	if ticket.Status != "active" || ticket.ScanCount > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only active, unused tickets can be transferred"})
+		return
+	}
+
+	if _, err := tc.listings.FindOpenByTicket(context.Background(), ticket.ID); err != repository.ErrNotFound {
+		if err == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Ticket is listed for resale"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	event, err := tc.events.FindByID(context.Background(), ticket.EventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if time.Now().After(event.Date) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Event has already taken place"})
+		return
+	}
+
+	transfer := models.Transfer{
+		TicketID:   ticket.ID,
+		EventID:    ticket.EventID,
+		FromUserID: user.ID,
+		ToEmail:    email,
+		Status:     "pending",
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+	if err := tc.transfers.Create(context.Background(), &transfer); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Ticket already has a pending transfer"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transfer"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// GetIncomingTransfers lists the transfers waiting for the caller to accept.
+func (tc *TransferController) GetIncomingTransfers(c *gin.Context) {
+	user, ok := tc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	transfers, err := tc.transfers.ListPendingByEmail(context.Background(), strings.ToLower(user.Email))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch transfers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transfers": transfers})
+}
+
+// AcceptTransfer moves the ticket to the caller and rotates its QR code so
+// the code the previous owner holds stops working.
+func (tc *TransferController) AcceptTransfer(c *gin.Context) {
+	user, ok := tc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	transfer, ok := tc.findTransfer(c)
+	if !ok {
+		return
+	}
+
+	// Only the recipient may accept; anyone else is told it does not exist
+	if transfer.ToEmail != strings.ToLower(user.Email) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		return
+	}
+
+	if transfer.Status != "pending" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Transfer is no longer pending"})
+		return
+	}
+
+	event, err := tc.events.FindByID(context.Background(), transfer.EventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Transfers count towards the recipient's per-user limit like bookings do
+	if event.MaxPerUser > 0 {
+		owned, err := tc.tickets.CountByUserAndEvent(context.Background(), user.ID, event.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		if owned+1 > event.MaxPerUser {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Accepting this ticket would exceed the per-user limit for this event"})
+			return
+		}
+	}
+
+	// Claim the transfer first so it cannot be cancelled or accepted twice
+	// while the ticket changes hands
+	if err := tc.transfers.Accept(context.Background(), transfer.ID, user.ID); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Transfer is no longer pending"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept transfer"})
+		return
+	}
+
+	record := models.TicketTransfer{
+		TransferID:    transfer.ID,
+		Method:        "transfer",
+		FromUserID:    transfer.FromUserID,
+		ToUserID:      user.ID,
+		TransferredAt: time.Now(),
+	}
+	qrCode := newTicketQR(transfer.TicketID, event)
+	if err := tc.tickets.TransferOwnership(context.Background(), transfer.TicketID, transfer.FromUserID, user.ID, qrCode, record); err != nil {
+		// The ticket was used, cancelled or moved since the offer was made
+		tc.transfers.TransitionStatus(context.Background(), transfer.ID, "accepted", "failed")
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Ticket can no longer be transferred"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to transfer ticket"})
+		return
+	}
+
+	ticket, err := tc.tickets.FindByID(context.Background(), transfer.TicketID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Ticket transferred successfully",
+		"ticket":  ticket,
+	})
+}
+
+func (tc *TransferController) DeclineTransfer(c *gin.Context) {
+	user, ok := tc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	transfer, ok := tc.findTransfer(c)
+	if !ok {
+		return
+	}
+
+	if transfer.ToEmail != strings.ToLower(user.Email) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		return
+	}
+
+	tc.finishTransfer(c, transfer, "declined", "Transfer declined")
+}
+
+func (tc *TransferController) CancelTransfer(c *gin.Context) {
+	user, ok := tc.currentUser(c)
+	if !ok {
+		return
+	}
+
+	transfer, ok := tc.findTransfer(c)
+	if !ok {
+		return
+	}
+
+	if transfer.FromUserID != user.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		return
+	}
+
+	tc.finishTransfer(c, transfer, "cancelled", "Transfer cancelled")
+}
+
+// finishTransfer closes a pending transfer without moving the ticket.
+func (tc *TransferController) finishTransfer(c *gin.Context, transfer *models.Transfer, status, message string) {
+	if err := tc.transfers.TransitionStatus(context.Background(), transfer.ID, "pending", status); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "Transfer is no longer pending"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update transfer"})
+		return
+	}
+
+	transfer.Status = status
+	c.JSON(http.StatusOK, gin.H{
+		"message":  message,
+		"transfer": transfer,
+	})
+}
+
+func (tc *TransferController) findTransfer(c *gin.Context) (*models.Transfer, bool) {
+	transferID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID"})
+		return nil, false
+	}
+
+	transfer, err := tc.transfers.FindByID(context.Background(), transferID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+	return transfer, true
+}
+
+// currentUser loads the calling user, whose email the token does not carry.
+func (tc *TransferController) currentUser(c *gin.Context) (*models.User, bool) {
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	user, err := tc.users.FindByID(context.Background(), userObjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return nil, false
+	}
+	return user, true
+}
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"server/models"
+	"server/repository"
+	"server/waitlist"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WaitlistController queues users for sold-out events. Seats are offered
+// to the queue by the waitlist service whenever inventory is released.
+type WaitlistController struct {
+	events   repository.EventRepository
+	tickets  repository.TicketRepository
+	holds    repository.HoldRepository
+	entries  repository.WaitlistRepository
+	waitlist *waitlist.Service
+}
+
+func NewWaitlistController(store *repository.Store, offers *waitlist.Service) *WaitlistController {
+	return &WaitlistController{
+		events:   store.Events,
+		tickets:  store.Tickets,
+		holds:    store.Holds,
+		entries:  store.Waitlist,
+		waitlist: offers,
+	}
+}
+
+// JoinWaitlist queues the caller for seats of a sold-out event or ticket
+// type.
+func (wc *WaitlistController) JoinWaitlist(c *gin.Context) {
+	eventID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	var req models.JoinWaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	if quantity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Quantity must be greater than 0"})
+		return
+	}
+
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	event, err := wc.events.FindByID(context.Background(), eventID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if reason := event.SalesClosedReason(time.Now()); reason != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": reason})
+		return
+	}
+
+	ticketTypeID, available, reason := resolveTicketType(event, req.TicketTypeID)
+	if reason != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": reason})
+		return
+	}
+
+	if available >= quantity {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tickets are still available for this event"})
+		return
+	}
+
+	// The offer is booked like any other hold, so the purchase limits apply
+	// when joining
+	reason, err = purchaseLimitReason(context.Background(), wc.tickets, wc.holds, event, userObjectID, quantity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if reason != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": reason})
+		return
+	}
+
+	entry := models.WaitlistEntry{
+		EventID:      eventID,
+		UserID:       userObjectID,
+		TicketTypeID: ticketTypeID,
+		Quantity:     quantity,
+		Status:       "waiting",
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if err := wc.entries.Create(context.Background(), &entry); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "You are already on the waitlist for this event"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join waitlist"})
+		return
+	}
+
+	// Seats released since the availability check would otherwise sit
+	// unoffered until the next release
+	if _, err := wc.waitlist.OfferSeats(context.Background(), eventID, ticketTypeID); err != nil {
+		log.Printf("Error offering seats of event %s to the waitlist: %v", eventID.Hex(), err)
+	}
+
+	wc.respondWithEntry(c, http.StatusCreated, eventID, userObjectID)
+}
+
+// GetWaitlistEntry shows the caller's place in the queue, or the offer
+// they have been made.
+func (wc *WaitlistController) GetWaitlistEntry(c *gin.Context) {
+	eventID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	wc.respondWithEntry(c, http.StatusOK, eventID, userObjectID)
+}
+
+// LeaveWaitlist removes the caller from the queue. Open offers are declined
+// by releasing their hold instead.
+func (wc *WaitlistController) LeaveWaitlist(c *gin.Context) {
+	eventID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		return
+	}
+
+	// Get user ID from context
+	userID, _ := c.Get("userID")
+	userObjectID, _ := primitive.ObjectIDFromHex(userID.(string))
+
+	entry, err := wc.entries.FindOpenByUser(context.Background(), eventID, userObjectID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "You are not on the waitlist for this event"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	if err := wc.entries.TransitionStatus(context.Background(), entry.ID, "waiting", "left"); err != nil {
+		if err == repository.ErrConflict {
+			c.JSON(http.StatusConflict, gin.H{"error": "You have an open offer, release its hold to decline it"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Left the waitlist"})
+}
+
+// respondWithEntry writes the caller's open entry for the event along with
+// their position while they are still waiting. The entry is re-read because
+// it may have been offered seats straight away.
+func (wc *WaitlistController) respondWithEntry(c *gin.Context, status int, eventID, userID primitive.ObjectID) {
+	entry, err := wc.entries.FindOpenByUser(context.Background(), eventID, userID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "You are not on the waitlist for this event"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	response := gin.H{"entry": entry}
+	if entry.Status == "waiting" {
+		ahead, err := wc.entries.CountWaitingBefore(context.Background(), entry)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		response["position"] = ahead + 1
+	}
+
+	c.JSON(status, response)
+}
@@ -0,0 +1,82 @@
+// Package documents renders printable documents for tickets.
+package documents
+
+import (
+	"bytes"
+	"fmt"
+	"server/models"
+	"server/utils"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// TicketPDF holds everything printed on a ticket PDF.
+type TicketPDF struct {
+	Event    *models.Event
+	Holder   *models.User
+	Currency string
+}
+
+// Render lays out one A5 page per ticket with the event details, the
+// holder's name and the ticket's QR code.
+func (d TicketPDF) Render(tickets []models.Ticket) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A5", "")
+	pdf.SetTitle(d.Event.Title, true)
+	pdf.SetCreator("Ticket Booking", true)
+	pdf.SetAutoPageBreak(false, 0)
+	// The core fonts only cover cp1252, so titles and names are translated
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	for _, ticket := range tickets {
+		pdf.AddPage()
+		pageWidth, _ := pdf.GetPageSize()
+		left, top, right, _ := pdf.GetMargins()
+		width := pageWidth - left - right
+
+		pdf.SetFont("Helvetica", "B", 20)
+		pdf.SetXY(left, top)
+		pdf.MultiCell(width, 9, tr(d.Event.Title), "", "L", false)
+		pdf.Ln(4)
+
+		fields := [][2]string{
+			{"Date", d.Event.Date.UTC().Format("Monday, 2 January 2006, 15:04 MST")},
+			{"Location", d.Event.Location},
+			{"Ticket holder", d.Holder.Name},
+			{"Price", fmt.Sprintf("%.2f %s", ticket.Price, strings.ToUpper(d.Currency))},
+		}
+		if ticket.TicketType != "" {
+			fields = append(fields, [2]string{"Ticket type", ticket.TicketType})
+		}
+		for _, field := range fields {
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.SetTextColor(110, 110, 110)
+			pdf.CellFormat(width, 5, strings.ToUpper(field[0]), "", 1, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 12)
+			pdf.SetTextColor(0, 0, 0)
+			pdf.MultiCell(width, 6, tr(field[1]), "", "L", false)
+			pdf.Ln(2)
+		}
+
+		png, err := utils.RenderQRPNG(ticket.QRCode, 512)
+		if err != nil {
+			return nil, err
+		}
+		imageName := "qr-" + ticket.ID.Hex()
+		pdf.RegisterImageOptionsReader(imageName, fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+
+		qrSize := 70.0
+		pdf.ImageOptions(imageName, (pageWidth-qrSize)/2, pdf.GetY()+4, qrSize, qrSize, false, fpdf.ImageOptions{}, 0, "")
+		pdf.SetY(pdf.GetY() + qrSize + 6)
+
+		pdf.SetFont("Courier", "", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(width, 4, "Ticket "+ticket.ID.Hex(), "", 1, "C", false, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
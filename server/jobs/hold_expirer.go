@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"server/repository"
+	"server/waitlist"
+	"time"
+)
+
+// HoldExpirer periodically returns the seats of holds that ran out before
+// the buyer checked out, offering them to the event's waitlist.
+type HoldExpirer struct {
+	holds    repository.HoldRepository
+	events   repository.EventRepository
+	waitlist *waitlist.Service
+	interval time.Duration
+}
+
+// NewHoldExpirer sweeps on the clock of offers, the waitlist service the
+// released seats are offered through, so tests drive both by replacing
+// its Now.
+func NewHoldExpirer(store *repository.Store, offers *waitlist.Service, interval time.Duration) *HoldExpirer {
+	return &HoldExpirer{
+		holds:    store.Holds,
+		events:   store.Events,
+		waitlist: offers,
+		interval: interval,
+	}
+}
+
+// Run sweeps for expired holds every interval until ctx is cancelled.
+func (e *HoldExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := e.ExpireDue(ctx); err != nil {
+				log.Printf("Error expiring holds: %v", err)
+			}
+		}
+	}
+}
+
+// ExpireDue releases every hold that has expired by now and returns how many
+// were released.
+func (e *HoldExpirer) ExpireDue(ctx context.Context) (int, error) {
+	holds, err := e.holds.ListExpired(ctx, e.waitlist.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, hold := range holds {
+		// A checkout may claim the hold between the listing and here; only
+		// the side that wins the transition touches the inventory
+		err := e.holds.TransitionStatus(ctx, hold.ID, "active", "expired")
+		if err == repository.ErrConflict {
+			continue
+		}
+		if err != nil {
+			return released, err
+		}
+
+		if err := e.events.ReleaseTickets(ctx, hold.EventID, hold.TicketTypeID, hold.Quantity); err != nil {
+			return released, err
+		}
+		released++
+
+		// A lapsed offer passes to the next user in the queue
+		if err := e.waitlist.CloseOffer(ctx, hold.ID, "expired"); err != nil {
+			return released, err
+		}
+		if _, err := e.waitlist.OfferSeats(ctx, hold.EventID, hold.TicketTypeID); err != nil {
+			return released, err
+		}
+	}
+	return released, nil
+}
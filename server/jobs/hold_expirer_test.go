@@ -0,0 +1,214 @@
+package jobs_test
+
+import (
+	"context"
+	"server/jobs"
+	"server/models"
+	"server/repository"
+	"server/waitlist"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var start = time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// createEvent stores a published event with every seat available.
+func createEvent(t *testing.T, store *repository.Store, capacity int) *models.Event {
+	t.Helper()
+
+	event := &models.Event{
+		ID:               primitive.NewObjectID(),
+		Title:            "Concert",
+		Date:             start.Add(30 * 24 * time.Hour),
+		Location:         "Main Hall",
+		Price:            25,
+		TotalTickets:     capacity,
+		AvailableTickets: capacity,
+		Status:           "published",
+		OrganizerID:      primitive.NewObjectID(),
+		CreatedAt:        start,
+		UpdatedAt:        start,
+	}
+	if err := store.Events.Create(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+// holdSeats takes quantity seats out of the event's inventory for a user
+// until expiresAt.
+func holdSeats(t *testing.T, store *repository.Store, eventID primitive.ObjectID, quantity int, expiresAt time.Time) *models.Hold {
+	t.Helper()
+	ctx := context.Background()
+
+	if err := store.Events.ReserveTickets(ctx, eventID, primitive.NilObjectID, quantity); err != nil {
+		t.Fatal(err)
+	}
+	hold := &models.Hold{
+		ID:        primitive.NewObjectID(),
+		EventID:   eventID,
+		UserID:    primitive.NewObjectID(),
+		Quantity:  quantity,
+		Status:    "active",
+		ExpiresAt: expiresAt,
+		CreatedAt: start,
+		UpdatedAt: start,
+	}
+	if err := store.Holds.Create(ctx, hold); err != nil {
+		t.Fatal(err)
+	}
+	return hold
+}
+
+func available(t *testing.T, store *repository.Store, eventID primitive.ObjectID) int {
+	t.Helper()
+
+	event, err := store.Events.FindByID(context.Background(), eventID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event.AvailableTickets
+}
+
+func holdStatus(t *testing.T, store *repository.Store, holdID primitive.ObjectID) string {
+	t.Helper()
+
+	hold, err := store.Holds.FindByID(context.Background(), holdID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hold.Status
+}
+
+func TestExpireDueReleasesLapsedHolds(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	event := createEvent(t, store, 5)
+	hold := holdSeats(t, store, event.ID, 2, start.Add(10*time.Minute))
+
+	now := start
+	offers := waitlist.NewService(store, 15*time.Minute)
+	offers.Now = func() time.Time { return now }
+	expirer := jobs.NewHoldExpirer(store, offers, time.Minute)
+
+	// Before the deadline the seats stay held
+	now = start.Add(9 * time.Minute)
+	released, err := expirer.ExpireDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released != 0 || holdStatus(t, store, hold.ID) != "active" {
+		t.Fatalf("released %d holds before expiry", released)
+	}
+	if got := available(t, store, event.ID); got != 3 {
+		t.Fatalf("available tickets = %d, want 3", got)
+	}
+
+	now = start.Add(11 * time.Minute)
+	released, err = expirer.ExpireDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released != 1 {
+		t.Fatalf("released %d holds, want 1", released)
+	}
+	if got := holdStatus(t, store, hold.ID); got != "expired" {
+		t.Errorf("hold status = %q, want expired", got)
+	}
+	if got := available(t, store, event.ID); got != 5 {
+		t.Errorf("available tickets = %d, want 5", got)
+	}
+
+	// A second sweep finds nothing left to release
+	released, err = expirer.ExpireDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released != 0 {
+		t.Errorf("released %d holds twice", released)
+	}
+	if got := available(t, store, event.ID); got != 5 {
+		t.Errorf("available tickets = %d after a second sweep, want 5", got)
+	}
+}
+
+func TestExpiredOfferPassesToNextInQueue(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	event := createEvent(t, store, 1)
+	hold := holdSeats(t, store, event.ID, 1, start.Add(10*time.Minute))
+
+	// Two users queue for the sold-out event
+	var entries []*models.WaitlistEntry
+	for i := 0; i < 2; i++ {
+		entry := &models.WaitlistEntry{
+			ID:        primitive.NewObjectID(),
+			EventID:   event.ID,
+			UserID:    primitive.NewObjectID(),
+			Quantity:  1,
+			Status:    "waiting",
+			CreatedAt: start.Add(time.Duration(i) * time.Second),
+			UpdatedAt: start.Add(time.Duration(i) * time.Second),
+		}
+		if err := store.Waitlist.Create(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+
+	const offerTTL = 15 * time.Minute
+	now := start
+	offers := waitlist.NewService(store, offerTTL)
+	offers.Now = func() time.Time { return now }
+	expirer := jobs.NewHoldExpirer(store, offers, time.Minute)
+
+	// The lapsed hold's seat is offered to the first user
+	now = start.Add(11 * time.Minute)
+	if _, err := expirer.ExpireDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := holdStatus(t, store, hold.ID); got != "expired" {
+		t.Fatalf("hold status = %q, want expired", got)
+	}
+	first, err := store.Waitlist.FindOpenByUser(ctx, event.ID, entries[0].UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Status != "offered" {
+		t.Fatalf("first entry status = %q, want offered", first.Status)
+	}
+	if want := now.Add(offerTTL); !first.OfferExpiresAt.Equal(want) {
+		t.Errorf("offer expires at %v, want %v", first.OfferExpiresAt, want)
+	}
+	if got := available(t, store, event.ID); got != 0 {
+		t.Fatalf("available tickets = %d while offered, want 0", got)
+	}
+
+	// The first user lets the offer lapse and it moves down the queue
+	now = now.Add(offerTTL + time.Minute)
+	if _, err := expirer.ExpireDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := holdStatus(t, store, first.HoldID); got != "expired" {
+		t.Errorf("offer hold status = %q, want expired", got)
+	}
+	lapsed, err := store.Waitlist.FindByHold(ctx, first.HoldID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lapsed.Status != "expired" {
+		t.Errorf("first entry status = %q, want expired", lapsed.Status)
+	}
+	second, err := store.Waitlist.FindOpenByUser(ctx, event.ID, entries[1].UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Status != "offered" {
+		t.Errorf("second entry status = %q, want offered", second.Status)
+	}
+	if got := available(t, store, event.ID); got != 0 {
+		t.Errorf("available tickets = %d while offered, want 0", got)
+	}
+}
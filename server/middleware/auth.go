@@ -61,3 +61,18 @@ func RoleRequired(allowedRoles ...string) gin.HandlerFunc {
 		c.Abort()
 	}
 }
+
+// OptionalAuth sets the user info like AuthRequired when a valid token is
+// sent, and lets anonymous requests through otherwise.
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if tokenString != "" {
+			if claims, err := utils.ValidateToken(tokenString); err == nil {
+				c.Set("userID", claims.UserID)
+				c.Set("role", claims.Role)
+			}
+		}
+		c.Next()
+	}
+}
@@ -1,39 +1,279 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Event struct {
-	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	Title            string             `json:"title" bson:"title" validate:"required"`
-	Description      string             `json:"description" bson:"description"`
-	Date             time.Time          `json:"date" bson:"date" validate:"required"`
-	Location         string             `json:"location" bson:"location" validate:"required"`
-	Price            float64            `json:"price" bson:"price" validate:"required,gte=0"`
-	TotalTickets     int                `json:"total_tickets" bson:"total_tickets" validate:"required,gt=0"`
+	ID                  primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Title               string             `json:"title" bson:"title" validate:"required"`
+	Description         string             `json:"description" bson:"description"`
+	Date                time.Time          `json:"date" bson:"date" validate:"required"`
+	EndDate             *time.Time         `json:"end_date,omitempty" bson:"end_date,omitempty"` // when a multi-day event finishes, unset for single-day ones
+	Location            string             `json:"location" bson:"location" validate:"required"`
+	Timezone            string             `json:"timezone,omitempty" bson:"timezone,omitempty"` // IANA name of the venue's zone, UTC when unset
+	Price               float64            `json:"price" bson:"price" validate:"required,gte=0"`
+	TotalTickets        int                `json:"total_tickets" bson:"total_tickets" validate:"required,gt=0"`
+	AvailableTickets    int                `json:"available_tickets" bson:"available_tickets"`
+	MaxPerOrder         int                `json:"max_per_order" bson:"max_per_order"` // 0 means no limit
+	MaxPerUser          int                `json:"max_per_user" bson:"max_per_user"`   // 0 means no limit, best-effort under concurrent bookings
+	TicketTypes         []TicketType       `json:"ticket_types" bson:"ticket_types"`
+	CancelDeadlineHours int                `json:"cancel_deadline_hours" bson:"cancel_deadline_hours"` // hours before Date that cancellations close
+	MaxScans            int                `json:"max_scans" bson:"max_scans"`                         // admissions per ticket, 0 means one
+	ScanLimitPerDay     bool               `json:"scan_limit_per_day" bson:"scan_limit_per_day"`       // MaxScans applies to each day in the venue's timezone
+	ResaleEnabled       bool               `json:"resale_enabled" bson:"resale_enabled"`
+	ResaleMaxPercent    float64            `json:"resale_max_percent" bson:"resale_max_percent"`       // cap as a percentage of face value, 0 means 100
+	ResaleFee           float64            `json:"resale_fee" bson:"resale_fee"`                       // flat fee added to each resale
+	Status              string             `json:"status" bson:"status"`                               // draft, published, sales_paused, cancelled or completed
+	SalesStart          *time.Time         `json:"sales_start,omitempty" bson:"sales_start,omitempty"` // unset opens sales on publishing
+	SalesEnd            *time.Time         `json:"sales_end,omitempty" bson:"sales_end,omitempty"`     // unset closes sales when the event starts
+	SalesStatus         string             `json:"sales_status,omitempty" bson:"-"`                    // upcoming, open or closed, set on responses
+	OrganizerID         primitive.ObjectID `json:"organizer_id" bson:"organizer_id"`
+	CreatedAt           time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt           time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// TicketType is a price category with its own capacity, such as VIP or
+// student tickets. When an event has ticket types, its TotalTickets and
+// AvailableTickets are the sums over all of them.
+type TicketType struct {
+	ID               primitive.ObjectID `json:"id" bson:"id"`
+	Name             string             `json:"name" bson:"name"`
+	Price            float64            `json:"price" bson:"price"`
+	TotalTickets     int                `json:"total_tickets" bson:"total_tickets"`
 	AvailableTickets int                `json:"available_tickets" bson:"available_tickets"`
-	OrganizerID      primitive.ObjectID `json:"organizer_id" bson:"organizer_id"`
-	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// CancelDeadline is the last moment ticket holders may cancel.
+func (e *Event) CancelDeadline() time.Time {
+	return e.Date.Add(-time.Duration(e.CancelDeadlineHours) * time.Hour)
+}
+
+// AdmissionsAllowed is how many times a ticket may be scanned in, in total
+// or per day depending on ScanLimitPerDay.
+func (e *Event) AdmissionsAllowed() int {
+	if e.MaxScans <= 0 {
+		return 1
+	}
+	return e.MaxScans
+}
+
+// EndsAt is when the event finishes: its end date for multi-day events,
+// otherwise its start.
+func (e *Event) EndsAt() time.Time {
+	if e.EndDate != nil {
+		return *e.EndDate
+	}
+	return e.Date
+}
+
+// VenueTimeZone is the zone that per-day scan limits count days in.
+func (e *Event) VenueTimeZone() *time.Location {
+	if e.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// AllowsReentry reports whether ticket holders may leave and come back in,
+// which requires them to be scanned out.
+func (e *Event) AllowsReentry() bool {
+	return e.AdmissionsAllowed() > 1 || e.ScanLimitPerDay
+}
+
+// FaceValue is the original price of a ticket of the given type, or of the
+// event when it has no ticket types.
+func (e *Event) FaceValue(ticketTypeID primitive.ObjectID) float64 {
+	if ticketType := e.FindTicketType(ticketTypeID); ticketType != nil {
+		return ticketType.Price
+	}
+	return e.Price
+}
+
+// MaxResalePrice is the most a ticket of the given type may be listed for,
+// not counting the resale fee.
+func (e *Event) MaxResalePrice(ticketTypeID primitive.ObjectID) float64 {
+	percent := e.ResaleMaxPercent
+	if percent <= 0 {
+		percent = 100
+	}
+	return math.Round(e.FaceValue(ticketTypeID)*percent) / 100
+}
+
+// eventTransitions lists the states each lifecycle state may move to.
+// Cancelled and completed events are final.
+var eventTransitions = map[string][]string{
+	"draft":        {"published", "cancelled"},
+	"published":    {"sales_paused", "cancelled", "completed"},
+	"sales_paused": {"published", "cancelled", "completed"},
+}
+
+// CurrentStatus is the event's lifecycle state. Events created before
+// states existed have none and count as published.
+func (e *Event) CurrentStatus() string {
+	if e.Status == "" {
+		return "published"
+	}
+	return e.Status
+}
+
+// CanTransitionTo reports whether the event may move to status from its
+// current state.
+func (e *Event) CanTransitionTo(status string) bool {
+	for _, next := range eventTransitions[e.CurrentStatus()] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// SalesClosedReason explains why tickets cannot be bought at now, or is
+// empty while the event is on sale.
+func (e *Event) SalesClosedReason(now time.Time) string {
+	switch e.CurrentStatus() {
+	case "draft":
+		return "Event has not been published"
+	case "sales_paused":
+		return "Ticket sales are paused for this event"
+	case "cancelled":
+		return "Event has been cancelled"
+	case "completed":
+		return "Event has already taken place"
+	}
+	if now.After(e.Date) {
+		return "Event has already taken place"
+	}
+	if e.SalesStart != nil && now.Before(*e.SalesStart) {
+		return "Ticket sales have not started yet"
+	}
+	if e.SalesEnd != nil && now.After(*e.SalesEnd) {
+		return "Ticket sales have ended"
+	}
+	return ""
+}
+
+// CurrentSalesStatus is "open" while tickets can be bought, "upcoming"
+// while the event still has to be published or its sales window has yet to
+// open, and "closed" otherwise.
+func (e *Event) CurrentSalesStatus(now time.Time) string {
+	if e.SalesClosedReason(now) == "" {
+		return "open"
+	}
+	status := e.CurrentStatus()
+	ended := now.After(e.Date) || e.SalesEnd != nil && now.After(*e.SalesEnd)
+	if (status == "draft" || status == "published") && !ended {
+		return "upcoming"
+	}
+	return "closed"
+}
+
+func (e *Event) FindTicketType(id primitive.ObjectID) *TicketType {
+	for i := range e.TicketTypes {
+		if e.TicketTypes[i].ID == id {
+			return &e.TicketTypes[i]
+		}
+	}
+	return nil
 }
 
 type CreateEventRequest struct {
-	Title        string    `json:"title" validate:"required"`
-	Description  string    `json:"description"`
-	Date         time.Time `json:"date" validate:"required"`
-	Location     string    `json:"location" validate:"required"`
-	Price        float64   `json:"price" validate:"required,gte=0"`
-	TotalTickets int       `json:"total_tickets" validate:"required,gt=0"`
+	Title               string     `json:"title" validate:"required"`
+	Description         string     `json:"description"`
+	Date                time.Time  `json:"date" validate:"required"`
+	EndDate             *time.Time `json:"end_date,omitempty"`
+	Location            string     `json:"location" validate:"required"`
+	Timezone            string     `json:"timezone,omitempty"`
+	Price               float64    `json:"price" validate:"required,gte=0"`
+	TotalTickets        int        `json:"total_tickets" validate:"required,gt=0"`
+	MaxPerOrder         int        `json:"max_per_order" validate:"gte=0"`
+	MaxPerUser          int        `json:"max_per_user" validate:"gte=0"`
+	CancelDeadlineHours int        `json:"cancel_deadline_hours" validate:"gte=0"`
+	MaxScans            int        `json:"max_scans" validate:"gte=0"`
+	ScanLimitPerDay     bool       `json:"scan_limit_per_day"`
+	ResaleEnabled       bool       `json:"resale_enabled"`
+	ResaleMaxPercent    float64    `json:"resale_max_percent" validate:"gte=0"`
+	ResaleFee           float64    `json:"resale_fee" validate:"gte=0"`
+	Status              string     `json:"status" validate:"omitempty,oneof=draft published"` // defaults to published
+	SalesStart          *time.Time `json:"sales_start,omitempty"`
+	SalesEnd            *time.Time `json:"sales_end,omitempty"`
+	// TicketTypes replaces Price and TotalTickets when given
+	TicketTypes []TicketTypeRequest `json:"ticket_types,omitempty"`
 }
 
 type UpdateEventRequest struct {
-	Title        *string    `json:"title,omitempty"`
-	Description  *string    `json:"description,omitempty"`
-	Date         *time.Time `json:"date,omitempty"`
-	Location     *string    `json:"location,omitempty"`
-	Price        *float64   `json:"price,omitempty" validate:"omitempty,gte=0"`
-	TotalTickets *int       `json:"total_tickets,omitempty" validate:"omitempty,gt=0"`
+	Title               *string    `json:"title,omitempty"`
+	Description         *string    `json:"description,omitempty"`
+	Date                *time.Time `json:"date,omitempty"`
+	EndDate             *time.Time `json:"end_date,omitempty"`
+	Location            *string    `json:"location,omitempty"`
+	Timezone            *string    `json:"timezone,omitempty"`
+	Price               *float64   `json:"price,omitempty" validate:"omitempty,gte=0"`
+	TotalTickets        *int       `json:"total_tickets,omitempty" validate:"omitempty,gt=0"`
+	MaxPerOrder         *int       `json:"max_per_order,omitempty" validate:"omitempty,gte=0"`
+	MaxPerUser          *int       `json:"max_per_user,omitempty" validate:"omitempty,gte=0"`
+	CancelDeadlineHours *int       `json:"cancel_deadline_hours,omitempty" validate:"omitempty,gte=0"`
+	MaxScans            *int       `json:"max_scans,omitempty" validate:"omitempty,gte=0"`
+	ScanLimitPerDay     *bool      `json:"scan_limit_per_day,omitempty"`
+	ResaleEnabled       *bool      `json:"resale_enabled,omitempty"`
+	ResaleMaxPercent    *float64   `json:"resale_max_percent,omitempty" validate:"omitempty,gte=0"`
+	ResaleFee           *float64   `json:"resale_fee,omitempty" validate:"omitempty,gte=0"`
+	SalesStart          *time.Time `json:"sales_start,omitempty"`
+	SalesEnd            *time.Time `json:"sales_end,omitempty"`
+	// Entries with an ID update that ticket type, entries without one are
+	// added. Ticket types that are not listed are left unchanged.
+	TicketTypes []TicketTypeRequest `json:"ticket_types,omitempty"`
+}
+
+type UpdateEventStatusRequest struct {
+	Status string `json:"status" validate:"required"`
+}
+
+// EventQuery filters and pages the public event listing. Nil and empty
+// fields do not filter.
+type EventQuery struct {
+	// From keeps events that have not ended by then; To keeps events that
+	// start by then
+	From        *time.Time
+	To          *time.Time
+	Location    string // case-insensitive substring
+	MinPrice    *float64
+	MaxPrice    *float64
+	OrganizerID *primitive.ObjectID
+	Available   *bool  // whether tickets are left
+	Search      string // case-insensitive substring of title or description
+	// IncludeDrafts lists draft events as well, for their organizer
+	IncludeDrafts bool
+	// After continues the listing behind this cursor; events are ordered by
+	// date, then ID
+	After *EventCursor
+	Limit int
+}
+
+// EventCursor is the position of the last event of a page.
+type EventCursor struct {
+	Date time.Time
+	ID   primitive.ObjectID
+}
+
+// EventPage is one page of an event listing. Total counts every event
+// matching the query, not only this page.
+type EventPage struct {
+	Events  []Event
+	Total   int
+	HasMore bool
+}
+
+type TicketTypeRequest struct {
+	ID           *primitive.ObjectID `json:"id,omitempty"`
+	Name         string              `json:"name" validate:"required"`
+	Price        float64             `json:"price" validate:"gte=0"`
+	TotalTickets int                 `json:"total_tickets" validate:"gt=0"`
 }
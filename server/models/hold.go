@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Hold takes seats out of an event's inventory for a limited time while the
+// buyer completes checkout.
+type Hold struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventID      primitive.ObjectID `json:"event_id" bson:"event_id"`
+	UserID       primitive.ObjectID `json:"user_id" bson:"user_id"`
+	TicketTypeID primitive.ObjectID `json:"ticket_type_id" bson:"ticket_type_id,omitempty"`
+	Quantity     int                `json:"quantity" bson:"quantity"`
+	Status       string             `json:"status" bson:"status"` // "active", "converted", "released", "expired"
+	ExpiresAt    time.Time          `json:"expires_at" bson:"expires_at"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Listing offers a ticket for resale on the event's official marketplace.
+// The buyer pays Price plus the organizer's Fee; once the payment is
+// captured the ticket moves to the buyer with a new QR code.
+type Listing struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TicketID     primitive.ObjectID `json:"ticket_id" bson:"ticket_id"`
+	EventID      primitive.ObjectID `json:"event_id" bson:"event_id"`
+	TicketTypeID primitive.ObjectID `json:"ticket_type_id" bson:"ticket_type_id,omitempty"`
+	SellerID     primitive.ObjectID `json:"seller_id" bson:"seller_id"`
+	BuyerID      primitive.ObjectID `json:"buyer_id,omitempty" bson:"buyer_id,omitempty"` // set once a purchase starts
+	OrderID      primitive.ObjectID `json:"order_id,omitempty" bson:"order_id,omitempty"`
+	Price        float64            `json:"price" bson:"price"`
+	Fee          float64            `json:"fee" bson:"fee"`
+	Status       string             `json:"status" bson:"status"` // "active", "reserved", "sold", "cancelled"
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// Total is what the buyer pays for the listing.
+func (l *Listing) Total() float64 {
+	return l.Price + l.Fee
+}
+
+type CreateListingRequest struct {
+	Price float64 `json:"price" validate:"gte=0"`
+}
+
+type BuyListingRequest struct {
+	PaymentToken string `json:"payment_token"`
+}
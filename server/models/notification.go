@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification is a message shown to a user in the app, such as the news
+// that an event they hold tickets for was cancelled.
+type Notification struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	EventID   primitive.ObjectID `json:"event_id,omitempty" bson:"event_id,omitempty"`
+	Kind      string             `json:"kind" bson:"kind"` // "event_cancelled"
+	Message   string             `json:"message" bson:"message"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
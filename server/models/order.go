@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Order struct {
+	ID           primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	EventID      primitive.ObjectID   `json:"event_id" bson:"event_id"`
+	UserID       primitive.ObjectID   `json:"user_id" bson:"user_id"`
+	TicketTypeID primitive.ObjectID   `json:"ticket_type_id" bson:"ticket_type_id,omitempty"`
+	HoldID       primitive.ObjectID   `json:"hold_id,omitempty" bson:"hold_id,omitempty"`
+	ListingID    primitive.ObjectID   `json:"listing_id,omitempty" bson:"listing_id,omitempty"` // set for resale purchases
+	PromoCodeID  primitive.ObjectID   `json:"promo_code_id,omitempty" bson:"promo_code_id,omitempty"`
+	TicketIDs    []primitive.ObjectID `json:"ticket_ids" bson:"ticket_ids"`
+	Quantity     int                  `json:"quantity" bson:"quantity"`
+	TotalPrice   float64              `json:"total_price" bson:"total_price"`
+	Status       string               `json:"status" bson:"status"` // "pending", "paid", "failed"
+	CreatedAt    time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at" bson:"updated_at"`
+}
+
+type BookTicketRequest struct {
+	Quantity int `json:"quantity" validate:"omitempty,gt=0"` // defaults to 1
+	// TicketTypeID is required when the event has ticket types
+	TicketTypeID *primitive.ObjectID `json:"ticket_type_id,omitempty"`
+	PaymentToken string              `json:"payment_token"`
+	// PromoCode is applied when booking; holds take it at checkout
+	PromoCode string `json:"promo_code,omitempty"`
+}
+
+type CheckoutHoldRequest struct {
+	PaymentToken string `json:"payment_token"`
+	PromoCode    string `json:"promo_code,omitempty"`
+}
+
+type BookOrderResponse struct {
+	OrderID    primitive.ObjectID   `json:"order_id"`
+	EventID    primitive.ObjectID   `json:"event_id"`
+	Quantity   int                  `json:"quantity"`
+	TotalPrice float64              `json:"total_price"`
+	Status     string               `json:"status"`
+	Tickets    []BookTicketResponse `json:"tickets"`
+	Message    string               `json:"message"`
+	CreatedAt  time.Time            `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Payment struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	OrderID         primitive.ObjectID `json:"order_id" bson:"order_id"`
+	UserID          primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Provider        string             `json:"provider" bson:"provider"`
+	AuthorizationID string             `json:"authorization_id" bson:"authorization_id"`
+	Amount          float64            `json:"amount" bson:"amount"`
+	Status          string             `json:"status" bson:"status"` // "authorized", "capture_pending", "captured", "failed"
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Payout is money owed to a seller for a ticket sold on the resale
+// marketplace: the listing price, without the fee the buyer paid on top.
+// Payouts are recorded "pending" and settled outside the system.
+type Payout struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"` // the seller
+	ListingID primitive.ObjectID `json:"listing_id" bson:"listing_id"`
+	OrderID   primitive.ObjectID `json:"order_id" bson:"order_id"`
+	EventID   primitive.ObjectID `json:"event_id" bson:"event_id"`
+	TicketID  primitive.ObjectID `json:"ticket_id" bson:"ticket_id"`
+	Amount    float64            `json:"amount" bson:"amount"`
+	Status    string             `json:"status" bson:"status"` // "pending", "paid"
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PromoCode discounts tickets of one event for buyers who enter it at
+// booking time.
+type PromoCode struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventID      primitive.ObjectID `json:"event_id" bson:"event_id"`
+	Code         string             `json:"code" bson:"code"`                   // stored upper case
+	DiscountType string             `json:"discount_type" bson:"discount_type"` // "percent", "fixed"
+	Amount       float64            `json:"amount" bson:"amount"`               // percent off, or currency off each ticket
+	MaxUses      int                `json:"max_uses" bson:"max_uses"`           // tickets it may discount in total, 0 means no limit
+	Uses         int                `json:"uses" bson:"uses"`
+	ValidFrom    *time.Time         `json:"valid_from,omitempty" bson:"valid_from,omitempty"`
+	ValidUntil   *time.Time         `json:"valid_until,omitempty" bson:"valid_until,omitempty"`
+	// TicketTypeIDs limits the code to these ticket types; empty means all
+	TicketTypeIDs []primitive.ObjectID `json:"ticket_type_ids,omitempty" bson:"ticket_type_ids,omitempty"`
+	Active        bool                 `json:"active" bson:"active"`
+	CreatedAt     time.Time            `json:"created_at" bson:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at" bson:"updated_at"`
+}
+
+// ValidAt reports whether the code can be redeemed at t, ignoring its
+// usage limit.
+func (p *PromoCode) ValidAt(t time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.ValidFrom != nil && t.Before(*p.ValidFrom) {
+		return false
+	}
+	if p.ValidUntil != nil && t.After(*p.ValidUntil) {
+		return false
+	}
+	return true
+}
+
+// AppliesTo reports whether the code discounts the ticket type.
+func (p *PromoCode) AppliesTo(ticketTypeID primitive.ObjectID) bool {
+	if len(p.TicketTypeIDs) == 0 {
+		return true
+	}
+	for _, id := range p.TicketTypeIDs {
+		if id == ticketTypeID {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the discounted price of a ticket, rounded to the cent and
+// never below zero.
+func (p *PromoCode) Apply(price float64) float64 {
+	discounted := price - p.Amount
+	if p.DiscountType == "percent" {
+		discounted = price * (100 - p.Amount) / 100
+	}
+	if discounted < 0 {
+		return 0
+	}
+	return math.Round(discounted*100) / 100
+}
+
+type CreatePromoCodeRequest struct {
+	Code          string               `json:"code" validate:"required"`
+	DiscountType  string               `json:"discount_type" validate:"required,oneof=percent fixed"`
+	Amount        float64              `json:"amount" validate:"gt=0"`
+	MaxUses       int                  `json:"max_uses" validate:"gte=0"`
+	ValidFrom     *time.Time           `json:"valid_from,omitempty"`
+	ValidUntil    *time.Time           `json:"valid_until,omitempty"`
+	TicketTypeIDs []primitive.ObjectID `json:"ticket_type_ids,omitempty"`
+}
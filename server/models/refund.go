@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Refund struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TicketID  primitive.ObjectID `json:"ticket_id" bson:"ticket_id"`
+	OrderID   primitive.ObjectID `json:"order_id" bson:"order_id,omitempty"`
+	EventID   primitive.ObjectID `json:"event_id" bson:"event_id"`
+	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Amount    float64            `json:"amount" bson:"amount"`
+	Reason    string             `json:"reason" bson:"reason"`
+	Status    string             `json:"status" bson:"status"` // "pending", "completed", "failed"
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+}
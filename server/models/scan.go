@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Scan records one attempt to validate a ticket at the door.
+type Scan struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TicketID  primitive.ObjectID `json:"ticket_id" bson:"ticket_id"`
+	EventID   primitive.ObjectID `json:"event_id" bson:"event_id"`
+	ScannedBy primitive.ObjectID `json:"scanned_by" bson:"scanned_by"`
+	Gate      string             `json:"gate,omitempty" bson:"gate,omitempty"`
+	Direction string             `json:"direction" bson:"direction"` // "in", "out"
+	// DeviceID is set for scans made offline and uploaded later
+	DeviceID  string    `json:"device_id,omitempty" bson:"device_id,omitempty"`
+	Result    string    `json:"result" bson:"result"` // "accepted", "duplicate", "rejected"
+	ScannedAt time.Time `json:"scanned_at" bson:"scanned_at"`
+}
+
+// TicketScan is an accepted scan in the log kept on the ticket.
+type TicketScan struct {
+	Direction string             `json:"direction" bson:"direction"` // "in", "out"
+	Gate      string             `json:"gate,omitempty" bson:"gate,omitempty"`
+	DeviceID  string             `json:"device_id,omitempty" bson:"device_id,omitempty"`
+	ScannedBy primitive.ObjectID `json:"scanned_by" bson:"scanned_by"`
+	ScannedAt time.Time          `json:"scanned_at" bson:"scanned_at"`
+}
+
+// ScanManifest lists the tickets a scanner may admit while offline.
+type ScanManifest struct {
+	EventID         primitive.ObjectID `json:"event_id"`
+	MaxScans        int                `json:"max_scans"`
+	ScanLimitPerDay bool               `json:"scan_limit_per_day"`
+	Timezone        string             `json:"timezone,omitempty"` // days for ScanLimitPerDay are counted in this zone, UTC when unset
+	ExpiresAt       time.Time          `json:"expires_at"`         // supersedes the expiry signed into codes issued before a date change
+	GeneratedAt     time.Time          `json:"generated_at"`
+	Tickets         []ManifestTicket   `json:"tickets"`
+}
+
+type ManifestTicket struct {
+	TicketID   primitive.ObjectID `json:"ticket_id"`
+	QRCode     string             `json:"qr_code"`
+	TicketType string             `json:"ticket_type,omitempty"`
+	ScanCount  int                `json:"scan_count"`
+	CheckedIn  bool               `json:"checked_in"`
+}
+
+type OfflineScan struct {
+	QRCode    string    `json:"qr_code"`
+	DeviceID  string    `json:"device_id"`
+	Gate      string    `json:"gate"`
+	Direction string    `json:"direction"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+type OfflineScanBatchRequest struct {
+	Scans []OfflineScan `json:"scans"`
+}
+
+// OfflineScanResult reports how one uploaded scan was reconciled.
+type OfflineScanResult struct {
+	OfflineScan
+	TicketID primitive.ObjectID `json:"ticket_id,omitempty"`
+	// Result is "accepted", "duplicate", "conflict", "invalid" or "synced"
+	// for a scan that was already uploaded before
+	Result string `json:"result"`
+	Reason string `json:"reason,omitempty"`
+	// UsedAt and UsedGate tell where a duplicate was first admitted
+	UsedAt   *time.Time `json:"used_at,omitempty"`
+	UsedGate string     `json:"used_gate,omitempty"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StaffAssignment lets a staff account validate tickets for one event.
+// Staff are invited by email so the assignment can be made before they have
+// registered, but since anyone can register with any email the assignment
+// only takes effect once the account with that email presents the invite's
+// single-use token, which binds it to UserID.
+type StaffAssignment struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventID         primitive.ObjectID `json:"event_id" bson:"event_id"`
+	Email           string             `json:"email" bson:"email"`
+	InvitedBy       primitive.ObjectID `json:"invited_by" bson:"invited_by"`
+	UserID          primitive.ObjectID `json:"user_id,omitempty" bson:"user_id,omitempty"` // set once accepted
+	InviteTokenHash string             `json:"-" bson:"invite_token_hash,omitempty"`       // cleared once accepted
+	InviteToken     string             `json:"invite_token,omitempty" bson:"-"`            // only returned when invited
+	AcceptedAt      *time.Time         `json:"accepted_at,omitempty" bson:"accepted_at,omitempty"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type InviteStaffRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type AcceptStaffInviteRequest struct {
+	Token string `json:"token" binding:"required"`
+}
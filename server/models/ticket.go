@@ -7,35 +7,72 @@ import (
 )
 
 type Ticket struct {
-	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
-	EventID   primitive.ObjectID `json:"event_id" bson:"event_id"`
-	UserID    primitive.ObjectID `json:"user_id" bson:"user_id"`
-	QRCode    string             `json:"qr_code" bson:"qr_code"`
-	Status    string             `json:"status" bson:"status"` // "active", "used", "cancelled"
-	Price     float64            `json:"price" bson:"price"`
-	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventID      primitive.ObjectID `json:"event_id" bson:"event_id"`
+	UserID       primitive.ObjectID `json:"user_id" bson:"user_id"`
+	OrderID      primitive.ObjectID `json:"order_id" bson:"order_id,omitempty"`
+	TicketTypeID primitive.ObjectID `json:"ticket_type_id" bson:"ticket_type_id,omitempty"` // zero for events without ticket types
+	TicketType   string             `json:"ticket_type,omitempty" bson:"ticket_type,omitempty"`
+	QRCode       string             `json:"qr_code" bson:"qr_code"`
+	Status       string             `json:"status" bson:"status"` // "pending", "active", "used", "cancelled"
+	Price        float64            `json:"price" bson:"price"`
+	// PromoCode is the code that took Discount off Price
+	PromoCode string  `json:"promo_code,omitempty" bson:"promo_code,omitempty"`
+	Discount  float64 `json:"discount,omitempty" bson:"discount,omitempty"`
+	// UsedAt and UsedGate record the latest admission
+	UsedAt    *time.Time `json:"used_at,omitempty" bson:"used_at,omitempty"`
+	UsedGate  string     `json:"used_gate,omitempty" bson:"used_gate,omitempty"`
+	ScanCount int        `json:"scan_count" bson:"scan_count,omitempty"` // admissions so far
+	CheckedIn bool       `json:"checked_in,omitempty" bson:"checked_in,omitempty"`
+	// Scans logs every accepted scan in and out. ScanVersion counts them so
+	// concurrent scanners can detect each other's writes.
+	Scans       []TicketScan `json:"scans,omitempty" bson:"scans,omitempty"`
+	ScanVersion int          `json:"-" bson:"scan_version,omitempty"`
+	// Transfers is the ticket's ownership history, oldest first
+	Transfers []TicketTransfer `json:"transfers,omitempty" bson:"transfers,omitempty"`
+	CreatedAt time.Time        `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at" bson:"updated_at"`
 }
 
 type TicketWithEvent struct {
-	ID        primitive.ObjectID `json:"id"`
-	Event     Event              `json:"event"`
-	QRCode    string             `json:"qr_code"`
-	Status    string             `json:"status"`
-	Price     float64            `json:"price"`
-	CreatedAt time.Time          `json:"created_at"`
+	ID         primitive.ObjectID `json:"id"`
+	Event      Event              `json:"event"`
+	TicketType string             `json:"ticket_type,omitempty"`
+	QRCode     string             `json:"qr_code"`
+	Status     string             `json:"status"`
+	Price      float64            `json:"price"`
+	CreatedAt  time.Time          `json:"created_at"`
 }
 
 type ValidateTicketRequest struct {
 	QRCode string `json:"qr_code" validate:"required"`
+	// Gate names the entrance the scanner is posted at
+	Gate string `json:"gate"`
+	// Direction is "in" (the default) or "out" for events with re-entry
+	Direction string `json:"direction"`
 }
 
 type BookTicketResponse struct {
-	ID        primitive.ObjectID `json:"id"`
-	EventID   primitive.ObjectID `json:"event_id"`
-	QRCode    string             `json:"qr_code"`
-	Status    string             `json:"status"`
-	Price     float64            `json:"price"`
-	Message   string             `json:"message"`
-	CreatedAt time.Time          `json:"created_at"`
+	ID         primitive.ObjectID `json:"id"`
+	EventID    primitive.ObjectID `json:"event_id"`
+	TicketType string             `json:"ticket_type,omitempty"`
+	QRCode     string             `json:"qr_code"`
+	Status     string             `json:"status"`
+	Price      float64            `json:"price"`
+	PromoCode  string             `json:"promo_code,omitempty"`
+	Discount   float64            `json:"discount,omitempty"`
+	Message    string             `json:"message,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+func NewTicketWithEvent(ticket Ticket, event Event) TicketWithEvent {
+	return TicketWithEvent{
+		ID:         ticket.ID,
+		Event:      event,
+		TicketType: ticket.TicketType,
+		QRCode:     ticket.QRCode,
+		Status:     ticket.Status,
+		Price:      ticket.Price,
+		CreatedAt:  ticket.CreatedAt,
+	}
 }
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Transfer offers a ticket to another user, who becomes its owner once they
+// accept.
+type Transfer struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TicketID   primitive.ObjectID `json:"ticket_id" bson:"ticket_id"`
+	EventID    primitive.ObjectID `json:"event_id" bson:"event_id"`
+	FromUserID primitive.ObjectID `json:"from_user_id" bson:"from_user_id"`
+	ToEmail    string             `json:"to_email" bson:"to_email"`
+	ToUserID   primitive.ObjectID `json:"to_user_id" bson:"to_user_id,omitempty"` // set once accepted
+	Status     string             `json:"status" bson:"status"`                   // "pending", "accepted", "declined", "cancelled", "failed"
+	CreatedAt  time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// TicketTransfer is an entry in the ownership history kept on a ticket.
+// TransferID refers to the Transfer, or for resales to the Listing, that
+// moved the ticket.
+type TicketTransfer struct {
+	TransferID    primitive.ObjectID `json:"transfer_id" bson:"transfer_id"`
+	Method        string             `json:"method" bson:"method"`                   // "transfer", "resale"
+	Price         float64            `json:"price,omitempty" bson:"price,omitempty"` // resale price paid to the previous owner
+	FromUserID    primitive.ObjectID `json:"from_user_id" bson:"from_user_id"`
+	ToUserID      primitive.ObjectID `json:"to_user_id" bson:"to_user_id"`
+	TransferredAt time.Time          `json:"transferred_at" bson:"transferred_at"`
+}
+
+type TransferTicketRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
@@ -11,7 +11,7 @@ type User struct {
 	Name      string             `json:"name" bson:"name" validate:"required"`
 	Email     string             `json:"email" bson:"email" validate:"required,email"`
 	Password  string             `json:"-" bson:"password" validate:"required,min=6"`
-	Role      string             `json:"role" bson:"role" validate:"required,oneof=user organizer"`
+	Role      string             `json:"role" bson:"role" validate:"required,oneof=user organizer staff"`
 	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
 }
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WaitlistEntry queues a user for seats of a sold-out event. When seats are
+// released the next waiting entry is offered them as a hold that the user
+// checks out like any other.
+type WaitlistEntry struct {
+	ID             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	EventID        primitive.ObjectID `json:"event_id" bson:"event_id"`
+	UserID         primitive.ObjectID `json:"user_id" bson:"user_id"`
+	TicketTypeID   primitive.ObjectID `json:"ticket_type_id" bson:"ticket_type_id,omitempty"`
+	Quantity       int                `json:"quantity" bson:"quantity"`
+	Status         string             `json:"status" bson:"status"`                       // "waiting", "offered", "booked", "declined", "expired", "left"
+	HoldID         primitive.ObjectID `json:"hold_id,omitempty" bson:"hold_id,omitempty"` // the offer, once made
+	OfferExpiresAt *time.Time         `json:"offer_expires_at,omitempty" bson:"offer_expires_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type JoinWaitlistRequest struct {
+	Quantity int `json:"quantity" validate:"omitempty,gt=0"` // defaults to 1
+	// TicketTypeID is required when the event has ticket types
+	TicketTypeID *primitive.ObjectID `json:"ticket_type_id,omitempty"`
+}
@@ -0,0 +1,155 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Payment tokens understood by FakeProvider. Any other token succeeds
+// immediately.
+const (
+	FakeTokenDecline = "tok_decline"
+	FakeTokenDelayed = "tok_delayed"
+	FakeTokenFailing = "tok_delayed_fail"
+)
+
+// FakeProvider is an in-process gateway for local development and tests. It
+// declines FakeTokenDecline at authorization and, for FakeTokenDelayed and
+// FakeTokenFailing, leaves captures pending and reports the outcome through
+// a signed webhook after WebhookDelay.
+type FakeProvider struct {
+	Secret       string
+	WebhookDelay time.Duration
+
+	// Deliver receives webhooks once they are due. When nil they are queued
+	// and can be collected with PendingWebhooks instead.
+	Deliver func(payload []byte, signature string)
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+	queued   []FakeWebhook
+}
+
+type FakeWebhook struct {
+	Payload   []byte
+	Signature string
+}
+
+type fakePayment struct {
+	amount   float64
+	token    string
+	refunded float64
+}
+
+func NewFakeProvider(secret string, webhookDelay time.Duration) *FakeProvider {
+	return &FakeProvider{
+		Secret:       secret,
+		WebhookDelay: webhookDelay,
+		payments:     make(map[string]*fakePayment),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Authorization, error) {
+	if req.Token == FakeTokenDecline {
+		return nil, ErrDeclined
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := "fake_auth_" + primitive.NewObjectID().Hex()
+	p.payments[id] = &fakePayment{amount: req.Amount, token: req.Token}
+	return &Authorization{ID: id}, nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, authorizationID string) (string, error) {
+	p.mu.Lock()
+	payment, ok := p.payments[authorizationID]
+	p.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("unknown authorization %s", authorizationID)
+	}
+
+	switch payment.token {
+	case FakeTokenDelayed:
+		p.scheduleWebhook(WebhookEvent{Type: WebhookCaptured, AuthorizationID: authorizationID})
+		return CapturePending, nil
+	case FakeTokenFailing:
+		p.scheduleWebhook(WebhookEvent{Type: WebhookFailed, AuthorizationID: authorizationID})
+		return CapturePending, nil
+	}
+	return CaptureCompleted, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, authorizationID string, amount float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[authorizationID]
+	if !ok {
+		return fmt.Errorf("unknown authorization %s", authorizationID)
+	}
+	if payment.refunded+amount > payment.amount {
+		return fmt.Errorf("refund exceeds captured amount")
+	}
+	payment.refunded += amount
+	return nil
+}
+
+func (p *FakeProvider) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	if !hmac.Equal([]byte(p.sign(payload)), []byte(signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// PendingWebhooks drains the webhooks queued while Deliver was nil.
+func (p *FakeProvider) PendingWebhooks() []FakeWebhook {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	queued := p.queued
+	p.queued = nil
+	return queued
+}
+
+func (p *FakeProvider) scheduleWebhook(event WebhookEvent) {
+	payload, _ := json.Marshal(event)
+	webhook := FakeWebhook{Payload: payload, Signature: p.sign(payload)}
+
+	p.mu.Lock()
+	deliver := p.Deliver
+	if deliver == nil {
+		p.queued = append(p.queued, webhook)
+	}
+	p.mu.Unlock()
+
+	if deliver != nil {
+		time.AfterFunc(p.WebhookDelay, func() {
+			deliver(webhook.Payload, webhook.Signature)
+		})
+	}
+}
+
+func (p *FakeProvider) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.Secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
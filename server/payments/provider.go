@@ -0,0 +1,61 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/config"
+)
+
+var (
+	ErrDeclined         = errors.New("payment declined")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+const (
+	CaptureCompleted = "completed"
+	CapturePending   = "pending"
+)
+
+const (
+	WebhookCaptured = "payment.captured"
+	WebhookFailed   = "payment.failed"
+)
+
+// Provider is a payment gateway. Payments are authorized first and captured
+// separately; a capture may complete later, in which case the provider
+// reports the outcome through a webhook.
+type Provider interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Authorization, error)
+	// Capture returns CaptureCompleted or CapturePending.
+	Capture(ctx context.Context, authorizationID string) (string, error)
+	Refund(ctx context.Context, authorizationID string, amount float64) error
+	// VerifyWebhook checks the signature of a webhook delivery and decodes it.
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
+
+type AuthorizeRequest struct {
+	Amount    float64
+	Currency  string
+	Reference string // our order ID
+	Token     string // payment method token from the client
+}
+
+type Authorization struct {
+	ID string
+}
+
+type WebhookEvent struct {
+	Type            string `json:"type"` // WebhookCaptured or WebhookFailed
+	AuthorizationID string `json:"authorization_id"`
+}
+
+// NewProvider returns the gateway selected in the configuration.
+func NewProvider(cfg *config.Config) (Provider, error) {
+	switch cfg.PaymentProvider {
+	case "fake":
+		return NewFakeProvider(cfg.PaymentWebhookSecret, cfg.PaymentWebhookDelay), nil
+	}
+	return nil, fmt.Errorf("unknown payment provider %q", cfg.PaymentProvider)
+}
@@ -0,0 +1,378 @@
+package payments
+
+import (
+	"context"
+	"log"
+	"server/models"
+	"server/repository"
+	"server/utils"
+	"server/waitlist"
+	"time"
+)
+
+// Service ties a Provider to orders: tickets are issued as "pending" and
+// only become "active" once their order's payment has been captured. Resale
+// orders instead hand the listed ticket to the buyer once captured.
+type Service struct {
+	provider Provider
+	currency string
+
+	events   repository.EventRepository
+	tickets  repository.TicketRepository
+	orders   repository.OrderRepository
+	payments repository.PaymentRepository
+	refunds  repository.RefundRepository
+	listings repository.ListingRepository
+	promos   repository.PromoCodeRepository
+	payouts  repository.PayoutRepository
+	waitlist *waitlist.Service
+}
+
+func NewService(store *repository.Store, provider Provider, currency string, offers *waitlist.Service) *Service {
+	return &Service{
+		provider: provider,
+		currency: currency,
+		events:   store.Events,
+		tickets:  store.Tickets,
+		orders:   store.Orders,
+		payments: store.Payments,
+		refunds:  store.Refunds,
+		listings: store.Listings,
+		promos:   store.Promos,
+		payouts:  store.Payouts,
+		waitlist: offers,
+	}
+}
+
+// Charge collects payment for a pending order and returns the order's new
+// status. A capture the provider completes later leaves the order
+// "pending" until its webhook arrives. When the payment fails the order is
+// marked "failed", its seats are returned and the error is passed back.
+func (s *Service) Charge(ctx context.Context, order *models.Order, token string) (string, error) {
+	// Nothing to collect for free tickets
+	if order.TotalPrice == 0 {
+		return "paid", s.completeOrder(ctx, order)
+	}
+
+	authorization, err := s.provider.Authorize(ctx, AuthorizeRequest{
+		Amount:    order.TotalPrice,
+		Currency:  s.currency,
+		Reference: order.ID.Hex(),
+		Token:     token,
+	})
+	if err != nil {
+		s.failOrder(ctx, order)
+		return "failed", err
+	}
+
+	payment := models.Payment{
+		OrderID:         order.ID,
+		UserID:          order.UserID,
+		Provider:        s.provider.Name(),
+		AuthorizationID: authorization.ID,
+		Amount:          order.TotalPrice,
+		Status:          "authorized",
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	if err := s.payments.Create(ctx, &payment); err != nil {
+		s.failOrder(ctx, order)
+		return "failed", err
+	}
+
+	result, err := s.provider.Capture(ctx, authorization.ID)
+	if err != nil {
+		s.payments.UpdateStatus(ctx, payment.ID, "failed")
+		s.failOrder(ctx, order)
+		return "failed", err
+	}
+
+	if result == CapturePending {
+		return "pending", s.payments.UpdateStatus(ctx, payment.ID, "capture_pending")
+	}
+
+	// The money has been taken and no webhook will follow, so the order is
+	// completed even if the payment record cannot be brought up to date
+	if err := s.payments.UpdateStatus(ctx, payment.ID, "captured"); err != nil {
+		log.Printf("Error marking payment %s of order %s captured: %v", payment.ID.Hex(), order.ID.Hex(), err)
+	}
+	return "paid", s.completeOrder(ctx, order)
+}
+
+// HandleWebhook verifies and applies a webhook delivery from the provider.
+// Deliveries are idempotent: repeats for a settled order change nothing.
+func (s *Service) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	payment, err := s.payments.FindByAuthorization(ctx, event.AuthorizationID)
+	if err != nil {
+		return err
+	}
+
+	order, err := s.orders.FindByID(ctx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case WebhookCaptured:
+		if err := s.payments.UpdateStatus(ctx, payment.ID, "captured"); err != nil {
+			return err
+		}
+		return s.completeOrder(ctx, order)
+	case WebhookFailed:
+		if err := s.payments.UpdateStatus(ctx, payment.ID, "failed"); err != nil {
+			return err
+		}
+		return s.failOrder(ctx, order)
+	}
+	return nil
+}
+
+// Refund pays a refund back through the payment that bought the ticket and
+// records the outcome on the refund. Tickets from orders without a payment
+// on file keep their refund "pending" for manual handling.
+func (s *Service) Refund(ctx context.Context, refund *models.Refund) error {
+	if refund.Amount == 0 {
+		refund.Status = "completed"
+		return s.refunds.UpdateStatus(ctx, refund.ID, refund.Status)
+	}
+
+	payment, err := s.payments.FindByOrder(ctx, refund.OrderID)
+	if err == repository.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	refund.Status = "completed"
+	if err := s.provider.Refund(ctx, payment.AuthorizationID, refund.Amount); err != nil {
+		refund.Status = "failed"
+	}
+	return s.refunds.UpdateStatus(ctx, refund.ID, refund.Status)
+}
+
+// RefundTicket records a refund of the holder's most recent paid
+// acquisition of the ticket and sends it to the provider. A ticket last
+// bought on the resale marketplace refunds its resale order to that buyer;
+// the seller keeps their payout. Otherwise the original order is refunded
+// to the original buyer, however many times the ticket was given away
+// since.
+//
+// A ticket is refunded once per order: calling it again returns the refund
+// already on record, so a cancellation that failed part way can be rerun.
+// It returns a nil refund while the order is still being paid for; the
+// ticket is refunded when the payment is captured.
+func (s *Service) RefundTicket(ctx context.Context, ticket *models.Ticket, reason string) (*models.Refund, error) {
+	refund := models.Refund{
+		TicketID:  ticket.ID,
+		OrderID:   ticket.OrderID,
+		EventID:   ticket.EventID,
+		UserID:    ticket.UserID,
+		Amount:    ticket.Price,
+		Reason:    reason,
+		Status:    "pending",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if len(ticket.Transfers) > 0 {
+		refund.UserID = ticket.Transfers[0].FromUserID
+	}
+	resold := false
+	for i := len(ticket.Transfers) - 1; i >= 0; i-- {
+		if ticket.Transfers[i].Method != "resale" {
+			continue
+		}
+		order, err := s.orders.FindPaidByListing(ctx, ticket.Transfers[i].TransferID)
+		if err != nil {
+			return nil, err
+		}
+		refund.OrderID = order.ID
+		refund.UserID = order.UserID
+		refund.Amount = order.TotalPrice
+		resold = true
+		break
+	}
+	if !resold {
+		order, err := s.orders.FindByID(ctx, ticket.OrderID)
+		if err != nil && err != repository.ErrNotFound {
+			return nil, err
+		}
+		if err == nil && order.Status != "paid" {
+			return nil, nil
+		}
+	}
+
+	if err := s.refunds.Create(ctx, &refund); err != nil {
+		if err == repository.ErrConflict {
+			return s.refunds.FindByTicketAndOrder(ctx, refund.TicketID, refund.OrderID)
+		}
+		return nil, err
+	}
+	if err := s.Refund(ctx, &refund); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// completeOrder activates the order's tickets once it has been paid for.
+func (s *Service) completeOrder(ctx context.Context, order *models.Order) error {
+	err := s.orders.TransitionStatus(ctx, order.ID, "pending", "paid")
+	if err == repository.ErrConflict {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !order.ListingID.IsZero() {
+		return s.completeResale(ctx, order)
+	}
+
+	activated, err := s.tickets.TransitionByOrder(ctx, order.ID, "pending", "active")
+	if err != nil {
+		return err
+	}
+
+	// Tickets cancelled with their event while the payment was still being
+	// processed are refunded now that it has been captured
+	if activated < order.Quantity {
+		return s.refundCancelledTickets(ctx, order)
+	}
+	return nil
+}
+
+func (s *Service) refundCancelledTickets(ctx context.Context, order *models.Order) error {
+	tickets, err := s.tickets.ListByEvent(ctx, order.EventID)
+	if err != nil {
+		return err
+	}
+	for i := range tickets {
+		if tickets[i].OrderID != order.ID || tickets[i].Status != "cancelled" {
+			continue
+		}
+		if _, err := s.RefundTicket(ctx, &tickets[i], "event cancelled by organizer"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completeResale moves a paid-for listed ticket to the buyer with a new QR
+// code and records what the seller is owed. If the ticket can no longer
+// change hands the buyer is refunded.
+func (s *Service) completeResale(ctx context.Context, order *models.Order) error {
+	listing, err := s.listings.FindByID(ctx, order.ListingID)
+	if err != nil {
+		return err
+	}
+	if err := s.listings.TransitionStatus(ctx, listing.ID, "reserved", "sold"); err != nil {
+		return err
+	}
+
+	event, err := s.events.FindByID(ctx, listing.EventID)
+	if err != nil {
+		return err
+	}
+
+	record := models.TicketTransfer{
+		TransferID:    listing.ID,
+		Method:        "resale",
+		Price:         listing.Price,
+		FromUserID:    listing.SellerID,
+		ToUserID:      order.UserID,
+		TransferredAt: time.Now(),
+	}
+	qrCode := utils.NewTicketQR(listing.TicketID, event.ID, event.EndsAt())
+	err = s.tickets.TransferOwnership(ctx, listing.TicketID, listing.SellerID, order.UserID, qrCode, record)
+	if err == nil {
+		return s.recordPayout(ctx, listing, order)
+	}
+	if err != repository.ErrConflict {
+		return err
+	}
+
+	// The seller's ticket was used or cancelled while the payment went through
+	s.listings.TransitionStatus(ctx, listing.ID, "sold", "cancelled")
+	refund := models.Refund{
+		TicketID:  listing.TicketID,
+		OrderID:   order.ID,
+		EventID:   order.EventID,
+		UserID:    order.UserID,
+		Amount:    order.TotalPrice,
+		Reason:    "resale could not be completed",
+		Status:    "pending",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.refunds.Create(ctx, &refund); err != nil {
+		return err
+	}
+	return s.Refund(ctx, &refund)
+}
+
+// recordPayout records the listing price as owed to the seller; the fee the
+// buyer paid on top is kept.
+func (s *Service) recordPayout(ctx context.Context, listing *models.Listing, order *models.Order) error {
+	payout := models.Payout{
+		UserID:    listing.SellerID,
+		ListingID: listing.ID,
+		OrderID:   order.ID,
+		EventID:   listing.EventID,
+		TicketID:  listing.TicketID,
+		Amount:    listing.Price,
+		Status:    "pending",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	err := s.payouts.Create(ctx, &payout)
+	if err == repository.ErrConflict {
+		return nil
+	}
+	return err
+}
+
+// failOrder cancels the order's unpaid tickets and returns their seats,
+// offering them to the waitlist. A failed resale puts the listing back on
+// sale instead.
+func (s *Service) failOrder(ctx context.Context, order *models.Order) error {
+	err := s.orders.TransitionStatus(ctx, order.ID, "pending", "failed")
+	if err == repository.ErrConflict {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !order.ListingID.IsZero() {
+		err := s.listings.TransitionStatus(ctx, order.ListingID, "reserved", "active")
+		if err == repository.ErrConflict {
+			return nil
+		}
+		return err
+	}
+
+	// Unpaid bookings do not count against the promo code's usage limit
+	if !order.PromoCodeID.IsZero() {
+		if err := s.promos.Release(ctx, order.PromoCodeID, order.Quantity); err != nil {
+			return err
+		}
+	}
+
+	cancelled, err := s.tickets.TransitionByOrder(ctx, order.ID, "pending", "cancelled")
+	if err != nil {
+		return err
+	}
+	if cancelled == 0 {
+		return nil
+	}
+	if err := s.events.ReleaseTickets(ctx, order.EventID, order.TicketTypeID, cancelled); err != nil {
+		return err
+	}
+	_, err = s.waitlist.OfferSeats(ctx, order.EventID, order.TicketTypeID)
+	return err
+}
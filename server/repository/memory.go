@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"server/models"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryDB is the shared backing store for the in-memory repositories. A
+// single mutex guards every collection so cross-collection operations see a
+// consistent view, mirroring what a Mongo transaction would give us.
+type memoryDB struct {
+	mu            sync.Mutex
+	events        map[primitive.ObjectID]models.Event
+	tickets       map[primitive.ObjectID]models.Ticket
+	orders        map[primitive.ObjectID]models.Order
+	holds         map[primitive.ObjectID]models.Hold
+	payments      map[primitive.ObjectID]models.Payment
+	refunds       map[primitive.ObjectID]models.Refund
+	scans         map[primitive.ObjectID]models.Scan
+	staff         map[primitive.ObjectID]models.StaffAssignment
+	transfers     map[primitive.ObjectID]models.Transfer
+	listings      map[primitive.ObjectID]models.Listing
+	waitlist      map[primitive.ObjectID]models.WaitlistEntry
+	promos        map[primitive.ObjectID]models.PromoCode
+	users         map[primitive.ObjectID]models.User
+	notifications map[primitive.ObjectID]models.Notification
+	payouts       map[primitive.ObjectID]models.Payout
+}
+
+// NewMemoryStore returns a Store backed entirely by process memory, so the
+// HTTP API can be exercised without a running MongoDB.
+func NewMemoryStore() *Store {
+	db := &memoryDB{
+		events:        make(map[primitive.ObjectID]models.Event),
+		tickets:       make(map[primitive.ObjectID]models.Ticket),
+		orders:        make(map[primitive.ObjectID]models.Order),
+		holds:         make(map[primitive.ObjectID]models.Hold),
+		payments:      make(map[primitive.ObjectID]models.Payment),
+		refunds:       make(map[primitive.ObjectID]models.Refund),
+		scans:         make(map[primitive.ObjectID]models.Scan),
+		staff:         make(map[primitive.ObjectID]models.StaffAssignment),
+		transfers:     make(map[primitive.ObjectID]models.Transfer),
+		listings:      make(map[primitive.ObjectID]models.Listing),
+		waitlist:      make(map[primitive.ObjectID]models.WaitlistEntry),
+		promos:        make(map[primitive.ObjectID]models.PromoCode),
+		users:         make(map[primitive.ObjectID]models.User),
+		notifications: make(map[primitive.ObjectID]models.Notification),
+		payouts:       make(map[primitive.ObjectID]models.Payout),
+	}
+	return &Store{
+		Events:        &memoryEventRepository{db: db},
+		Tickets:       &memoryTicketRepository{db: db},
+		Orders:        &memoryOrderRepository{db: db},
+		Holds:         &memoryHoldRepository{db: db},
+		Payments:      &memoryPaymentRepository{db: db},
+		Refunds:       &memoryRefundRepository{db: db},
+		Scans:         &memoryScanRepository{db: db},
+		Staff:         &memoryStaffRepository{db: db},
+		Transfers:     &memoryTransferRepository{db: db},
+		Listings:      &memoryListingRepository{db: db},
+		Waitlist:      &memoryWaitlistRepository{db: db},
+		Promos:        &memoryPromoCodeRepository{db: db},
+		Users:         &memoryUserRepository{db: db},
+		Notifications: &memoryNotificationRepository{db: db},
+		Payouts:       &memoryPayoutRepository{db: db},
+	}
+}
@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"server/models"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryEventRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryEventRepository) Search(ctx context.Context, query models.EventQuery) (*models.EventPage, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	events := []models.Event{}
+	for _, event := range r.db.events {
+		if matchesEventQuery(event, query) {
+			event.TicketTypes = cloneTicketTypes(event.TicketTypes)
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return eventBefore(events[i], events[j].Date, events[j].ID) })
+
+	page := &models.EventPage{Total: len(events)}
+	if query.After != nil {
+		start := sort.Search(len(events), func(i int) bool {
+			return !eventBefore(events[i], query.After.Date, query.After.ID) && events[i].ID != query.After.ID
+		})
+		events = events[start:]
+	}
+	if len(events) > query.Limit {
+		events = events[:query.Limit]
+		page.HasMore = true
+	}
+	page.Events = events
+	return page, nil
+}
+
+// eventBefore orders events by date, then ID, the same way the listing is
+// sorted.
+func eventBefore(event models.Event, date time.Time, id primitive.ObjectID) bool {
+	if !event.Date.Equal(date) {
+		return event.Date.Before(date)
+	}
+	return bytes.Compare(event.ID[:], id[:]) < 0
+}
+
+func matchesEventQuery(event models.Event, query models.EventQuery) bool {
+	if query.From != nil && event.EndsAt().Before(*query.From) {
+		return false
+	}
+	if query.To != nil && event.Date.After(*query.To) {
+		return false
+	}
+	if query.MinPrice != nil && event.Price < *query.MinPrice {
+		return false
+	}
+	if query.MaxPrice != nil && event.Price > *query.MaxPrice {
+		return false
+	}
+	if query.Location != "" && !containsFold(event.Location, query.Location) {
+		return false
+	}
+	if query.OrganizerID != nil && event.OrganizerID != *query.OrganizerID {
+		return false
+	}
+	if query.Available != nil && (event.AvailableTickets > 0) != *query.Available {
+		return false
+	}
+	if !query.IncludeDrafts && event.Status == "draft" {
+		return false
+	}
+	if query.Search != "" && !containsFold(event.Title, query.Search) && !containsFold(event.Description, query.Search) {
+		return false
+	}
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func (r *memoryEventRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Event, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	event, ok := r.db.events[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	event.TicketTypes = cloneTicketTypes(event.TicketTypes)
+	return &event, nil
+}
+
+func (r *memoryEventRepository) Create(ctx context.Context, event *models.Event) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if event.ID.IsZero() {
+		event.ID = primitive.NewObjectID()
+	}
+	stored := *event
+	stored.TicketTypes = cloneTicketTypes(event.TicketTypes)
+	r.db.events[event.ID] = stored
+	return nil
+}
+
+func (r *memoryEventRepository) Update(ctx context.Context, event *models.Event) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	existing, ok := r.db.events[event.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	existing.Title = event.Title
+	existing.Description = event.Description
+	existing.Date = event.Date
+	existing.EndDate = event.EndDate
+	existing.Location = event.Location
+	existing.Timezone = event.Timezone
+	existing.Price = event.Price
+	existing.MaxPerOrder = event.MaxPerOrder
+	existing.MaxPerUser = event.MaxPerUser
+	existing.MaxScans = event.MaxScans
+	existing.ScanLimitPerDay = event.ScanLimitPerDay
+	existing.ResaleEnabled = event.ResaleEnabled
+	existing.ResaleMaxPercent = event.ResaleMaxPercent
+	existing.ResaleFee = event.ResaleFee
+	existing.SalesStart = event.SalesStart
+	existing.SalesEnd = event.SalesEnd
+	existing.CancelDeadlineHours = event.CancelDeadlineHours
+	existing.UpdatedAt = event.UpdatedAt
+	r.db.events[event.ID] = existing
+	return nil
+}
+
+func (r *memoryEventRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	event, ok := r.db.events[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if event.AvailableTickets != event.TotalTickets {
+		return ErrConflict
+	}
+	delete(r.db.events, id)
+	return nil
+}
+
+func (r *memoryEventRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	event, ok := r.db.events[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if event.CurrentStatus() != from {
+		return ErrConflict
+	}
+	event.Status = to
+	event.UpdatedAt = time.Now()
+	r.db.events[id] = event
+	return nil
+}
+
+func (r *memoryEventRepository) ReserveTickets(ctx context.Context, id, ticketTypeID primitive.ObjectID, quantity int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	event, ok := r.db.events[id]
+	if !ok || event.AvailableTickets < quantity {
+		return ErrSoldOut
+	}
+	if !ticketTypeID.IsZero() {
+		ticketType := event.FindTicketType(ticketTypeID)
+		if ticketType == nil || ticketType.AvailableTickets < quantity {
+			return ErrSoldOut
+		}
+	}
+	r.db.events[id] = adjustInventory(event, ticketTypeID, 0, -quantity)
+	return nil
+}
+
+func (r *memoryEventRepository) ReleaseTickets(ctx context.Context, id, ticketTypeID primitive.ObjectID, quantity int) error {
+	return r.adjust(id, ticketTypeID, 0, quantity)
+}
+
+func (r *memoryEventRepository) AdjustCapacity(ctx context.Context, id, ticketTypeID primitive.ObjectID, delta int) error {
+	if delta >= 0 {
+		return r.adjust(id, ticketTypeID, delta, delta)
+	}
+
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	event, ok := r.db.events[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if event.AvailableTickets < -delta {
+		return ErrConflict
+	}
+	if !ticketTypeID.IsZero() {
+		ticketType := event.FindTicketType(ticketTypeID)
+		if ticketType == nil {
+			return ErrNotFound
+		}
+		if ticketType.AvailableTickets < -delta {
+			return ErrConflict
+		}
+	}
+	r.db.events[id] = adjustInventory(event, ticketTypeID, delta, delta)
+	return nil
+}
+
+func (r *memoryEventRepository) AddTicketType(ctx context.Context, id primitive.ObjectID, ticketType models.TicketType) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	event, ok := r.db.events[id]
+	if !ok {
+		return ErrNotFound
+	}
+	event.TicketTypes = append(cloneTicketTypes(event.TicketTypes), ticketType)
+	event.TotalTickets += ticketType.TotalTickets
+	event.AvailableTickets += ticketType.AvailableTickets
+	r.db.events[id] = event
+	return nil
+}
+
+func (r *memoryEventRepository) UpdateTicketType(ctx context.Context, id primitive.ObjectID, ticketType models.TicketType) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	event, ok := r.db.events[id]
+	if !ok {
+		return ErrNotFound
+	}
+	event.TicketTypes = cloneTicketTypes(event.TicketTypes)
+	existing := event.FindTicketType(ticketType.ID)
+	if existing == nil {
+		return ErrNotFound
+	}
+	existing.Name = ticketType.Name
+	existing.Price = ticketType.Price
+	r.db.events[id] = event
+	return nil
+}
+
+func (r *memoryEventRepository) adjust(id, ticketTypeID primitive.ObjectID, totalDelta, availableDelta int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	event, ok := r.db.events[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if !ticketTypeID.IsZero() && event.FindTicketType(ticketTypeID) == nil {
+		return ErrNotFound
+	}
+	r.db.events[id] = adjustInventory(event, ticketTypeID, totalDelta, availableDelta)
+	return nil
+}
+
+// adjustInventory moves the event's counters, and those of the given ticket
+// type if any, by the same amounts. The ticket types slice is copied first
+// so events previously handed out to callers are not mutated.
+func adjustInventory(event models.Event, ticketTypeID primitive.ObjectID, totalDelta, availableDelta int) models.Event {
+	event.TotalTickets += totalDelta
+	event.AvailableTickets += availableDelta
+	if !ticketTypeID.IsZero() {
+		event.TicketTypes = cloneTicketTypes(event.TicketTypes)
+		ticketType := event.FindTicketType(ticketTypeID)
+		ticketType.TotalTickets += totalDelta
+		ticketType.AvailableTickets += availableDelta
+	}
+	return event
+}
+
+func cloneTicketTypes(ticketTypes []models.TicketType) []models.TicketType {
+	if ticketTypes == nil {
+		return nil
+	}
+	return append([]models.TicketType(nil), ticketTypes...)
+}
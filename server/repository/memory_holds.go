@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryHoldRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryHoldRepository) Create(ctx context.Context, hold *models.Hold) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if hold.ID.IsZero() {
+		hold.ID = primitive.NewObjectID()
+	}
+	r.db.holds[hold.ID] = *hold
+	return nil
+}
+
+func (r *memoryHoldRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Hold, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	hold, ok := r.db.holds[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &hold, nil
+}
+
+func (r *memoryHoldRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	hold, ok := r.db.holds[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if hold.Status != from {
+		return ErrConflict
+	}
+	hold.Status = to
+	hold.UpdatedAt = time.Now()
+	r.db.holds[id] = hold
+	return nil
+}
+
+func (r *memoryHoldRepository) ListExpired(ctx context.Context, now time.Time) ([]models.Hold, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var holds []models.Hold
+	for _, hold := range r.db.holds {
+		if hold.Status == "active" && !hold.ExpiresAt.After(now) {
+			holds = append(holds, hold)
+		}
+	}
+	return holds, nil
+}
+
+func (r *memoryHoldRepository) ListActiveByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.Hold, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var holds []models.Hold
+	for _, hold := range r.db.holds {
+		if hold.EventID == eventID && hold.Status == "active" {
+			holds = append(holds, hold)
+		}
+	}
+	return holds, nil
+}
+
+func (r *memoryHoldRepository) SumActiveByUserAndEvent(ctx context.Context, userID, eventID primitive.ObjectID) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	total := 0
+	for _, hold := range r.db.holds {
+		if hold.UserID == userID && hold.EventID == eventID && hold.Status == "active" {
+			total += hold.Quantity
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryListingRepository struct {
+	db *memoryDB
+}
+
+func isOpenListing(listing models.Listing) bool {
+	return listing.Status == "active" || listing.Status == "reserved"
+}
+
+func (r *memoryListingRepository) Create(ctx context.Context, listing *models.Listing) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.listings {
+		if existing.TicketID == listing.TicketID && isOpenListing(existing) {
+			return ErrConflict
+		}
+	}
+	if listing.ID.IsZero() {
+		listing.ID = primitive.NewObjectID()
+	}
+	r.db.listings[listing.ID] = *listing
+	return nil
+}
+
+func (r *memoryListingRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Listing, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	listing, ok := r.db.listings[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &listing, nil
+}
+
+func (r *memoryListingRepository) FindOpenByTicket(ctx context.Context, ticketID primitive.ObjectID) (*models.Listing, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, listing := range r.db.listings {
+		if listing.TicketID == ticketID && isOpenListing(listing) {
+			return &listing, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryListingRepository) ListActiveByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.Listing, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	listings := []models.Listing{}
+	for _, listing := range r.db.listings {
+		if listing.EventID == eventID && listing.Status == "active" {
+			listings = append(listings, listing)
+		}
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		if listings[i].Price != listings[j].Price {
+			return listings[i].Price < listings[j].Price
+		}
+		return listings[i].CreatedAt.Before(listings[j].CreatedAt)
+	})
+	return listings, nil
+}
+
+func (r *memoryListingRepository) Reserve(ctx context.Context, id, buyerID, orderID primitive.ObjectID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	listing, ok := r.db.listings[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if listing.Status != "active" {
+		return ErrConflict
+	}
+	listing.Status = "reserved"
+	listing.BuyerID = buyerID
+	listing.OrderID = orderID
+	listing.UpdatedAt = time.Now()
+	r.db.listings[id] = listing
+	return nil
+}
+
+func (r *memoryListingRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	listing, ok := r.db.listings[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if listing.Status != from {
+		return ErrConflict
+	}
+	listing.Status = to
+	listing.UpdatedAt = time.Now()
+	r.db.listings[id] = listing
+	return nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryNotificationRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryNotificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if notification.ID.IsZero() {
+		notification.ID = primitive.NewObjectID()
+	}
+	r.db.notifications[notification.ID] = *notification
+	return nil
+}
+
+func (r *memoryNotificationRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Notification, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	notifications := []models.Notification{}
+	for _, notification := range r.db.notifications {
+		if notification.UserID == userID {
+			notifications = append(notifications, notification)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].CreatedAt.After(notifications[j].CreatedAt)
+	})
+	return notifications, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryOrderRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryOrderRepository) Create(ctx context.Context, order *models.Order) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if order.ID.IsZero() {
+		order.ID = primitive.NewObjectID()
+	}
+	r.db.orders[order.ID] = *order
+	return nil
+}
+
+func (r *memoryOrderRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Order, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	order, ok := r.db.orders[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &order, nil
+}
+
+func (r *memoryOrderRepository) FindPaidByListing(ctx context.Context, listingID primitive.ObjectID) (*models.Order, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, order := range r.db.orders {
+		if order.ListingID == listingID && order.Status == "paid" {
+			return &order, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryOrderRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	order, ok := r.db.orders[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if order.Status != from {
+		return ErrConflict
+	}
+	order.Status = to
+	order.UpdatedAt = time.Now()
+	r.db.orders[id] = order
+	return nil
+}
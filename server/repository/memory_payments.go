@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryPaymentRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryPaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if payment.ID.IsZero() {
+		payment.ID = primitive.NewObjectID()
+	}
+	r.db.payments[payment.ID] = *payment
+	return nil
+}
+
+func (r *memoryPaymentRepository) FindByOrder(ctx context.Context, orderID primitive.ObjectID) (*models.Payment, error) {
+	return r.find(func(payment models.Payment) bool { return payment.OrderID == orderID })
+}
+
+func (r *memoryPaymentRepository) FindByAuthorization(ctx context.Context, authorizationID string) (*models.Payment, error) {
+	return r.find(func(payment models.Payment) bool { return payment.AuthorizationID == authorizationID })
+}
+
+func (r *memoryPaymentRepository) find(match func(models.Payment) bool) (*models.Payment, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, payment := range r.db.payments {
+		if match(payment) {
+			return &payment, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPaymentRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	payment, ok := r.db.payments[id]
+	if !ok {
+		return ErrNotFound
+	}
+	payment.Status = status
+	payment.UpdatedAt = time.Now()
+	r.db.payments[id] = payment
+	return nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryPayoutRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryPayoutRepository) Create(ctx context.Context, payout *models.Payout) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.payouts {
+		if existing.ListingID == payout.ListingID {
+			return ErrConflict
+		}
+	}
+	if payout.ID.IsZero() {
+		payout.ID = primitive.NewObjectID()
+	}
+	r.db.payouts[payout.ID] = *payout
+	return nil
+}
+
+func (r *memoryPayoutRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Payout, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	payouts := []models.Payout{}
+	for _, payout := range r.db.payouts {
+		if payout.UserID == userID {
+			payouts = append(payouts, payout)
+		}
+	}
+	sort.Slice(payouts, func(i, j int) bool {
+		return payouts[i].CreatedAt.After(payouts[j].CreatedAt)
+	})
+	return payouts, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryPromoCodeRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryPromoCodeRepository) Create(ctx context.Context, promo *models.PromoCode) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.promos {
+		if existing.EventID == promo.EventID && existing.Code == promo.Code {
+			return ErrConflict
+		}
+	}
+	if promo.ID.IsZero() {
+		promo.ID = primitive.NewObjectID()
+	}
+	r.db.promos[promo.ID] = *promo
+	return nil
+}
+
+func (r *memoryPromoCodeRepository) FindByCode(ctx context.Context, eventID primitive.ObjectID, code string) (*models.PromoCode, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, promo := range r.db.promos {
+		if promo.EventID == eventID && promo.Code == code {
+			return &promo, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPromoCodeRepository) ListByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.PromoCode, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	promos := []models.PromoCode{}
+	for _, promo := range r.db.promos {
+		if promo.EventID == eventID {
+			promos = append(promos, promo)
+		}
+	}
+	sort.Slice(promos, func(i, j int) bool { return promos[i].CreatedAt.Before(promos[j].CreatedAt) })
+	return promos, nil
+}
+
+func (r *memoryPromoCodeRepository) Deactivate(ctx context.Context, eventID, id primitive.ObjectID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	promo, ok := r.db.promos[id]
+	if !ok || promo.EventID != eventID {
+		return ErrNotFound
+	}
+	promo.Active = false
+	promo.UpdatedAt = time.Now()
+	r.db.promos[id] = promo
+	return nil
+}
+
+func (r *memoryPromoCodeRepository) Redeem(ctx context.Context, id primitive.ObjectID, quantity int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	promo, ok := r.db.promos[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if !promo.Active || (promo.MaxUses > 0 && promo.Uses+quantity > promo.MaxUses) {
+		return ErrConflict
+	}
+	promo.Uses += quantity
+	promo.UpdatedAt = time.Now()
+	r.db.promos[id] = promo
+	return nil
+}
+
+func (r *memoryPromoCodeRepository) Release(ctx context.Context, id primitive.ObjectID, quantity int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	promo, ok := r.db.promos[id]
+	if !ok || promo.Uses < quantity {
+		return ErrNotFound
+	}
+	promo.Uses -= quantity
+	promo.UpdatedAt = time.Now()
+	r.db.promos[id] = promo
+	return nil
+}
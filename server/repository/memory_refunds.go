@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryRefundRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryRefundRepository) Create(ctx context.Context, refund *models.Refund) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.refunds {
+		if !refund.OrderID.IsZero() && existing.TicketID == refund.TicketID && existing.OrderID == refund.OrderID {
+			return ErrConflict
+		}
+	}
+
+	if refund.ID.IsZero() {
+		refund.ID = primitive.NewObjectID()
+	}
+	r.db.refunds[refund.ID] = *refund
+	return nil
+}
+
+func (r *memoryRefundRepository) FindByTicketAndOrder(ctx context.Context, ticketID, orderID primitive.ObjectID) (*models.Refund, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, refund := range r.db.refunds {
+		if refund.TicketID == ticketID && refund.OrderID == orderID {
+			return &refund, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryRefundRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	refund, ok := r.db.refunds[id]
+	if !ok {
+		return ErrNotFound
+	}
+	refund.Status = status
+	refund.UpdatedAt = time.Now()
+	r.db.refunds[id] = refund
+	return nil
+}
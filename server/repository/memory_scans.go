@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryScanRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryScanRepository) Create(ctx context.Context, scan *models.Scan) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if scan.DeviceID != "" {
+		for _, existing := range r.db.scans {
+			if existing.TicketID == scan.TicketID && existing.DeviceID == scan.DeviceID && existing.ScannedAt.Equal(scan.ScannedAt) {
+				return ErrConflict
+			}
+		}
+	}
+	if scan.ID.IsZero() {
+		scan.ID = primitive.NewObjectID()
+	}
+	r.db.scans[scan.ID] = *scan
+	return nil
+}
+
+func (r *memoryScanRepository) ExistsFromDevice(ctx context.Context, ticketID primitive.ObjectID, deviceID string, scannedAt time.Time) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, scan := range r.db.scans {
+		if scan.TicketID == ticketID && scan.DeviceID == deviceID && scan.ScannedAt.Equal(scannedAt) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
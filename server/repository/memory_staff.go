@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryStaffRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryStaffRepository) Create(ctx context.Context, assignment *models.StaffAssignment) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.staff {
+		if existing.EventID == assignment.EventID && existing.Email == assignment.Email {
+			return ErrConflict
+		}
+	}
+	if assignment.ID.IsZero() {
+		assignment.ID = primitive.NewObjectID()
+	}
+	r.db.staff[assignment.ID] = *assignment
+	return nil
+}
+
+func (r *memoryStaffRepository) ListByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.StaffAssignment, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	assignments := []models.StaffAssignment{}
+	for _, assignment := range r.db.staff {
+		if assignment.EventID == eventID {
+			assignments = append(assignments, assignment)
+		}
+	}
+	sort.Slice(assignments, func(i, j int) bool {
+		return assignments[i].CreatedAt.Before(assignments[j].CreatedAt)
+	})
+	return assignments, nil
+}
+
+func (r *memoryStaffRepository) Delete(ctx context.Context, eventID, id primitive.ObjectID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	assignment, ok := r.db.staff[id]
+	if !ok || assignment.EventID != eventID {
+		return ErrNotFound
+	}
+	delete(r.db.staff, id)
+	return nil
+}
+
+func (r *memoryStaffRepository) Accept(ctx context.Context, tokenHash, email string, userID primitive.ObjectID, acceptedAt time.Time) (*models.StaffAssignment, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for id, assignment := range r.db.staff {
+		if assignment.InviteTokenHash == "" || assignment.InviteTokenHash != tokenHash || assignment.Email != email {
+			continue
+		}
+		assignment.UserID = userID
+		assignment.InviteTokenHash = ""
+		assignment.AcceptedAt = &acceptedAt
+		r.db.staff[id] = assignment
+		return &assignment, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryStaffRepository) IsAssigned(ctx context.Context, eventID, userID primitive.ObjectID) (bool, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, assignment := range r.db.staff {
+		if assignment.EventID == eventID && !assignment.UserID.IsZero() && assignment.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTicketRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryTicketRepository) Create(ctx context.Context, ticket *models.Ticket) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if ticket.ID.IsZero() {
+		ticket.ID = primitive.NewObjectID()
+	}
+	r.db.tickets[ticket.ID] = *ticket
+	return nil
+}
+
+func (r *memoryTicketRepository) CreateMany(ctx context.Context, tickets []models.Ticket) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for i := range tickets {
+		if tickets[i].ID.IsZero() {
+			tickets[i].ID = primitive.NewObjectID()
+		}
+		r.db.tickets[tickets[i].ID] = tickets[i]
+	}
+	return nil
+}
+
+func (r *memoryTicketRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Ticket, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	ticket, ok := r.db.tickets[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &ticket, nil
+}
+
+func (r *memoryTicketRepository) FindByQRCode(ctx context.Context, qrCode string) (*models.Ticket, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, ticket := range r.db.tickets {
+		if ticket.QRCode == qrCode {
+			return &ticket, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryTicketRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.TicketWithEvent, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var result []models.TicketWithEvent
+	for _, ticket := range r.db.tickets {
+		if ticket.UserID != userID {
+			continue
+		}
+		// Tickets whose event is gone are dropped, matching $unwind
+		event, ok := r.db.events[ticket.EventID]
+		if !ok {
+			continue
+		}
+		result = append(result, models.NewTicketWithEvent(ticket, event))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result, nil
+}
+
+func (r *memoryTicketRepository) ListByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.Ticket, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var tickets []models.Ticket
+	for _, ticket := range r.db.tickets {
+		if ticket.EventID == eventID {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets, nil
+}
+
+func (r *memoryTicketRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	ticket, ok := r.db.tickets[id]
+	if !ok {
+		return ErrNotFound
+	}
+	ticket.Status = status
+	ticket.UpdatedAt = time.Now()
+	r.db.tickets[id] = ticket
+	return nil
+}
+
+func (r *memoryTicketRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	ticket, ok := r.db.tickets[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if ticket.Status != from {
+		return ErrConflict
+	}
+	ticket.Status = to
+	ticket.UpdatedAt = time.Now()
+	r.db.tickets[id] = ticket
+	return nil
+}
+
+func (r *memoryTicketRepository) RecordScan(ctx context.Context, ticket *models.Ticket, from string, scan models.TicketScan) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	stored, ok := r.db.tickets[ticket.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if stored.Status != from || stored.ScanVersion != ticket.ScanVersion {
+		return ErrConflict
+	}
+	stored.Status = ticket.Status
+	stored.ScanCount = ticket.ScanCount
+	stored.CheckedIn = ticket.CheckedIn
+	stored.UsedAt = ticket.UsedAt
+	stored.UsedGate = ticket.UsedGate
+	stored.Scans = append(append([]models.TicketScan(nil), stored.Scans...), scan)
+	stored.ScanVersion++
+	stored.UpdatedAt = time.Now()
+	r.db.tickets[ticket.ID] = stored
+	return nil
+}
+
+func (r *memoryTicketRepository) TransferOwnership(ctx context.Context, id, fromUserID, toUserID primitive.ObjectID, qrCode string, record models.TicketTransfer) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	ticket, ok := r.db.tickets[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if ticket.UserID != fromUserID || ticket.Status != "active" {
+		return ErrConflict
+	}
+	ticket.UserID = toUserID
+	ticket.QRCode = qrCode
+	ticket.Transfers = append(append([]models.TicketTransfer(nil), ticket.Transfers...), record)
+	ticket.UpdatedAt = time.Now()
+	r.db.tickets[id] = ticket
+	return nil
+}
+
+func (r *memoryTicketRepository) CountByUserAndEvent(ctx context.Context, userID, eventID primitive.ObjectID) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	count := 0
+	for _, ticket := range r.db.tickets {
+		if ticket.UserID == userID && ticket.EventID == eventID && ticket.Status != "cancelled" {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryTicketRepository) DeleteByOrder(ctx context.Context, orderID primitive.ObjectID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for id, ticket := range r.db.tickets {
+		if ticket.OrderID == orderID {
+			delete(r.db.tickets, id)
+		}
+	}
+	return nil
+}
+
+func (r *memoryTicketRepository) TransitionByOrder(ctx context.Context, orderID primitive.ObjectID, from, to string) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	changed := 0
+	for id, ticket := range r.db.tickets {
+		if ticket.OrderID == orderID && ticket.Status == from {
+			ticket.Status = to
+			ticket.UpdatedAt = time.Now()
+			r.db.tickets[id] = ticket
+			changed++
+		}
+	}
+	return changed, nil
+}
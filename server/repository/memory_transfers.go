@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryTransferRepository struct {
+	db *memoryDB
+}
+
+func (r *memoryTransferRepository) Create(ctx context.Context, transfer *models.Transfer) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.transfers {
+		if existing.TicketID == transfer.TicketID && existing.Status == "pending" {
+			return ErrConflict
+		}
+	}
+	if transfer.ID.IsZero() {
+		transfer.ID = primitive.NewObjectID()
+	}
+	r.db.transfers[transfer.ID] = *transfer
+	return nil
+}
+
+func (r *memoryTransferRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Transfer, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	transfer, ok := r.db.transfers[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &transfer, nil
+}
+
+func (r *memoryTransferRepository) ListPendingByEmail(ctx context.Context, email string) ([]models.Transfer, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	transfers := []models.Transfer{}
+	for _, transfer := range r.db.transfers {
+		if transfer.ToEmail == email && transfer.Status == "pending" {
+			transfers = append(transfers, transfer)
+		}
+	}
+	sort.Slice(transfers, func(i, j int) bool { return transfers[i].CreatedAt.After(transfers[j].CreatedAt) })
+	return transfers, nil
+}
+
+func (r *memoryTransferRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	transfer, ok := r.db.transfers[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if transfer.Status != from {
+		return ErrConflict
+	}
+	transfer.Status = to
+	transfer.UpdatedAt = time.Now()
+	r.db.transfers[id] = transfer
+	return nil
+}
+
+func (r *memoryTransferRepository) Accept(ctx context.Context, id, toUserID primitive.ObjectID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	transfer, ok := r.db.transfers[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if transfer.Status != "pending" {
+		return ErrConflict
+	}
+	transfer.Status = "accepted"
+	transfer.ToUserID = toUserID
+	transfer.UpdatedAt = time.Now()
+	r.db.transfers[id] = transfer
+	return nil
+}
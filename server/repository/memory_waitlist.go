@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryWaitlistRepository struct {
+	db *memoryDB
+}
+
+func isOpenWaitlistEntry(entry models.WaitlistEntry) bool {
+	return entry.Status == "waiting" || entry.Status == "offered"
+}
+
+func (r *memoryWaitlistRepository) Create(ctx context.Context, entry *models.WaitlistEntry) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.waitlist {
+		if existing.EventID == entry.EventID && existing.UserID == entry.UserID && isOpenWaitlistEntry(existing) {
+			return ErrConflict
+		}
+	}
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+	r.db.waitlist[entry.ID] = *entry
+	return nil
+}
+
+func (r *memoryWaitlistRepository) FindOpenByUser(ctx context.Context, eventID, userID primitive.ObjectID) (*models.WaitlistEntry, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, entry := range r.db.waitlist {
+		if entry.EventID == eventID && entry.UserID == userID && isOpenWaitlistEntry(entry) {
+			return &entry, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryWaitlistRepository) FindByHold(ctx context.Context, holdID primitive.ObjectID) (*models.WaitlistEntry, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, entry := range r.db.waitlist {
+		if entry.HoldID == holdID {
+			return &entry, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryWaitlistRepository) NextWaiting(ctx context.Context, eventID, ticketTypeID primitive.ObjectID) (*models.WaitlistEntry, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var next *models.WaitlistEntry
+	for _, entry := range r.db.waitlist {
+		if entry.EventID != eventID || entry.TicketTypeID != ticketTypeID || entry.Status != "waiting" {
+			continue
+		}
+		if next == nil || entry.CreatedAt.Before(next.CreatedAt) {
+			candidate := entry
+			next = &candidate
+		}
+	}
+	if next == nil {
+		return nil, ErrNotFound
+	}
+	return next, nil
+}
+
+func (r *memoryWaitlistRepository) CountWaitingBefore(ctx context.Context, entry *models.WaitlistEntry) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	count := 0
+	for _, other := range r.db.waitlist {
+		if other.EventID == entry.EventID && other.TicketTypeID == entry.TicketTypeID &&
+			other.Status == "waiting" && other.CreatedAt.Before(entry.CreatedAt) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryWaitlistRepository) Offer(ctx context.Context, id, holdID primitive.ObjectID, expiresAt time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	entry, ok := r.db.waitlist[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if entry.Status != "waiting" {
+		return ErrConflict
+	}
+	entry.Status = "offered"
+	entry.HoldID = holdID
+	entry.OfferExpiresAt = &expiresAt
+	entry.UpdatedAt = time.Now()
+	r.db.waitlist[id] = entry
+	return nil
+}
+
+func (r *memoryWaitlistRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	entry, ok := r.db.waitlist[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if entry.Status != from {
+		return ErrConflict
+	}
+	entry.Status = to
+	entry.UpdatedAt = time.Now()
+	r.db.waitlist[id] = entry
+	return nil
+}
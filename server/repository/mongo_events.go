@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoEventRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoEventRepository) Search(ctx context.Context, query models.EventQuery) (*models.EventPage, error) {
+	filter := eventQueryFilter(query)
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.After != nil {
+		filter = bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+			bson.M{"date": bson.M{"$gt": query.After.Date}},
+			bson.M{"date": query.After.Date, "_id": bson.M{"$gt": query.After.ID}},
+		}}}}
+	}
+
+	// Fetch one extra event to tell whether another page follows
+	opts := options.Find().
+		SetSort(bson.D{{Key: "date", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(query.Limit + 1))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := []models.Event{}
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	page := &models.EventPage{Events: events, Total: int(total)}
+	if len(events) > query.Limit {
+		page.Events = events[:query.Limit]
+		page.HasMore = true
+	}
+	return page, nil
+}
+
+// eventQueryFilter translates the query's filters, leaving out the cursor.
+func eventQueryFilter(query models.EventQuery) bson.M {
+	filter := bson.M{}
+	if query.From != nil {
+		// Multi-day events stay listed until they end
+		filter["$expr"] = bson.M{"$gte": bson.A{bson.M{"$ifNull": bson.A{"$end_date", "$date"}}, *query.From}}
+	}
+	if query.To != nil {
+		filter["date"] = bson.M{"$lte": *query.To}
+	}
+	if query.MinPrice != nil || query.MaxPrice != nil {
+		price := bson.M{}
+		if query.MinPrice != nil {
+			price["$gte"] = *query.MinPrice
+		}
+		if query.MaxPrice != nil {
+			price["$lte"] = *query.MaxPrice
+		}
+		filter["price"] = price
+	}
+	if query.Location != "" {
+		filter["location"] = bson.M{"$regex": regexp.QuoteMeta(query.Location), "$options": "i"}
+	}
+	if query.OrganizerID != nil {
+		filter["organizer_id"] = *query.OrganizerID
+	}
+	if query.Available != nil {
+		if *query.Available {
+			filter["available_tickets"] = bson.M{"$gt": 0}
+		} else {
+			filter["available_tickets"] = bson.M{"$lte": 0}
+		}
+	}
+	if !query.IncludeDrafts {
+		filter["status"] = bson.M{"$ne": "draft"}
+	}
+	if query.Search != "" {
+		pattern := bson.M{"$regex": regexp.QuoteMeta(query.Search), "$options": "i"}
+		filter["$or"] = bson.A{bson.M{"title": pattern}, bson.M{"description": pattern}}
+	}
+	return filter
+}
+
+func (r *mongoEventRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Event, error) {
+	var event models.Event
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&event)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *mongoEventRepository) Create(ctx context.Context, event *models.Event) error {
+	result, err := r.collection.InsertOne(ctx, event)
+	if err != nil {
+		return err
+	}
+	event.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoEventRepository) Update(ctx context.Context, event *models.Event) error {
+	update := bson.M{
+		"title":                 event.Title,
+		"description":           event.Description,
+		"date":                  event.Date,
+		"end_date":              event.EndDate,
+		"location":              event.Location,
+		"timezone":              event.Timezone,
+		"price":                 event.Price,
+		"max_per_order":         event.MaxPerOrder,
+		"max_per_user":          event.MaxPerUser,
+		"cancel_deadline_hours": event.CancelDeadlineHours,
+		"max_scans":             event.MaxScans,
+		"scan_limit_per_day":    event.ScanLimitPerDay,
+		"resale_enabled":        event.ResaleEnabled,
+		"resale_max_percent":    event.ResaleMaxPercent,
+		"resale_fee":            event.ResaleFee,
+		"sales_start":           event.SalesStart,
+		"sales_end":             event.SalesEnd,
+		"updated_at":            event.UpdatedAt,
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": event.ID}, bson.M{"$set": update})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoEventRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	// Checked in the same operation so a booking cannot slip in between
+	result, err := r.collection.DeleteOne(ctx, bson.M{
+		"_id":   id,
+		"$expr": bson.M{"$eq": bson.A{"$available_tickets", "$total_tickets"}},
+	})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoEventRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	filter := bson.M{"_id": id, "status": from}
+	if from == "published" {
+		// Events stored before states existed have no status
+		filter["status"] = bson.M{"$in": bson.A{"published", nil}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"status": to, "updated_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoEventRepository) ReserveTickets(ctx context.Context, id, ticketTypeID primitive.ObjectID, quantity int) error {
+	// Conditional decrement so concurrent bookings can never push
+	// available_tickets below zero
+	filter, inc := inventoryUpdate(id, ticketTypeID, bson.M{"available_tickets": -quantity})
+	filter["available_tickets"] = bson.M{"$gte": quantity}
+	if !ticketTypeID.IsZero() {
+		filter["ticket_types"] = bson.M{"$elemMatch": bson.M{
+			"id":                ticketTypeID,
+			"available_tickets": bson.M{"$gte": quantity},
+		}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": inc})
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return ErrSoldOut
+	}
+	return nil
+}
+
+func (r *mongoEventRepository) ReleaseTickets(ctx context.Context, id, ticketTypeID primitive.ObjectID, quantity int) error {
+	filter, inc := inventoryUpdate(id, ticketTypeID, bson.M{"available_tickets": quantity})
+	return r.increment(ctx, filter, inc)
+}
+
+func (r *mongoEventRepository) AdjustCapacity(ctx context.Context, id, ticketTypeID primitive.ObjectID, delta int) error {
+	filter, inc := inventoryUpdate(id, ticketTypeID, bson.M{"total_tickets": delta, "available_tickets": delta})
+	if delta >= 0 {
+		return r.increment(ctx, filter, inc)
+	}
+
+	// Only unsold seats can be removed. Checking for them in the same update
+	// keeps a concurrent booking from taking them in between.
+	removed := -delta
+	filter["available_tickets"] = bson.M{"$gte": removed}
+	if !ticketTypeID.IsZero() {
+		filter["ticket_types"] = bson.M{"$elemMatch": bson.M{
+			"id":                ticketTypeID,
+			"available_tickets": bson.M{"$gte": removed},
+		}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": inc})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		event, err := r.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !ticketTypeID.IsZero() && event.FindTicketType(ticketTypeID) == nil {
+			return ErrNotFound
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoEventRepository) AddTicketType(ctx context.Context, id primitive.ObjectID, ticketType models.TicketType) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$push": bson.M{"ticket_types": ticketType},
+			"$inc": bson.M{
+				"total_tickets":     ticketType.TotalTickets,
+				"available_tickets": ticketType.AvailableTickets,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoEventRepository) UpdateTicketType(ctx context.Context, id primitive.ObjectID, ticketType models.TicketType) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "ticket_types.id": ticketType.ID},
+		bson.M{"$set": bson.M{
+			"ticket_types.$.name":  ticketType.Name,
+			"ticket_types.$.price": ticketType.Price,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoEventRepository) increment(ctx context.Context, filter, inc bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$inc": inc})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// inventoryUpdate builds the filter and $inc document for an inventory
+// change. The event-level counters always move; when ticketTypeID is set the
+// matching ticket type's counters move with them in the same update.
+func inventoryUpdate(id, ticketTypeID primitive.ObjectID, fields bson.M) (bson.M, bson.M) {
+	filter := bson.M{"_id": id}
+	inc := bson.M{}
+	for field, value := range fields {
+		inc[field] = value
+		if !ticketTypeID.IsZero() {
+			inc["ticket_types.$."+field] = value
+		}
+	}
+	if !ticketTypeID.IsZero() {
+		filter["ticket_types.id"] = ticketTypeID
+	}
+	return filter, inc
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoHoldRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoHoldRepository) Create(ctx context.Context, hold *models.Hold) error {
+	result, err := r.collection.InsertOne(ctx, hold)
+	if err != nil {
+		return err
+	}
+	hold.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoHoldRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Hold, error) {
+	var hold models.Hold
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&hold)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (r *mongoHoldRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "status": from},
+		bson.M{"$set": bson.M{"status": to, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoHoldRepository) ListExpired(ctx context.Context, now time.Time) ([]models.Hold, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"status": "active", "expires_at": bson.M{"$lte": now}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var holds []models.Hold
+	if err := cursor.All(ctx, &holds); err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *mongoHoldRepository) ListActiveByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.Hold, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"event_id": eventID, "status": "active"})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var holds []models.Hold
+	if err := cursor.All(ctx, &holds); err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *mongoHoldRepository) SumActiveByUserAndEvent(ctx context.Context, userID, eventID primitive.ObjectID) (int, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"user_id": userID, "event_id": eventID, "status": "active"}},
+		{"$group": bson.M{"_id": nil, "quantity": bson.M{"$sum": "$quantity"}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Quantity int `bson:"quantity"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	return results[0].Quantity, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoIndexes are created when the store is built. The unique ones back
+// the upserts the repositories use to refuse duplicates, which on their own
+// can both insert when two requests race. Partial filters using $in need
+// MongoDB 6.0 or later.
+var mongoIndexes = map[string][]mongo.IndexModel{
+	"staff_assignments": {
+		{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "user_id", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "invite_token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"invite_token_hash": bson.M{"$exists": true}}),
+		},
+	},
+	"transfers": {
+		{
+			Keys: bson.D{{Key: "ticket_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": "pending"}),
+		},
+		{Keys: bson.D{{Key: "to_email", Value: 1}, {Key: "status", Value: 1}}},
+	},
+	"listings": {
+		{
+			Keys: bson.D{{Key: "ticket_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": bson.M{"$in": openListingStatuses}}),
+		},
+		{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "status", Value: 1}}},
+	},
+	"waitlist": {
+		{
+			Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": bson.M{"$in": openWaitlistStatuses}}),
+		},
+		{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "ticket_type_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "hold_id", Value: 1}}},
+	},
+	"promo_codes": {
+		{Keys: bson.D{{Key: "event_id", Value: 1}, {Key: "code", Value: 1}}, Options: options.Index().SetUnique(true)},
+	},
+	"tickets": {
+		{Keys: bson.D{{Key: "qr_code", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "event_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "order_id", Value: 1}}},
+	},
+	"refunds": {
+		// A ticket is refunded once per order that paid for it
+		{
+			Keys: bson.D{{Key: "ticket_id", Value: 1}, {Key: "order_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"order_id": bson.M{"$exists": true}}),
+		},
+	},
+	"orders": {
+		{Keys: bson.D{{Key: "listing_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+	},
+	"payouts": {
+		{Keys: bson.D{{Key: "listing_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	},
+	"scans": {
+		// An offline scan uploaded twice is only recorded once
+		{
+			Keys: bson.D{{Key: "ticket_id", Value: 1}, {Key: "device_id", Value: 1}, {Key: "scanned_at", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"device_id": bson.M{"$exists": true}}),
+		},
+	},
+}
+
+func ensureMongoIndexes(ctx context.Context, db *mongo.Database) error {
+	for collection, indexes := range mongoIndexes {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, indexes); err != nil {
+			return fmt.Errorf("creating indexes on %s: %w", collection, err)
+		}
+	}
+	return nil
+}
+
+// duplicateKey maps unique index violations to ErrConflict.
+func duplicateKey(err error) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestDuplicateKey(t *testing.T) {
+	duplicate := mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000, Message: "E11000 duplicate key error"}}}
+	if err := duplicateKey(duplicate); err != ErrConflict {
+		t.Errorf("duplicate key error mapped to %v, want ErrConflict", err)
+	}
+
+	other := errors.New("connection reset")
+	if err := duplicateKey(other); err != other {
+		t.Errorf("other error mapped to %v", err)
+	}
+	if err := duplicateKey(nil); err != nil {
+		t.Errorf("nil mapped to %v", err)
+	}
+}
+
+func TestMongoIndexesCoverUpsertedCollections(t *testing.T) {
+	// Each of these refuses duplicates with an upsert that needs a unique
+	// index to hold under concurrency
+	for _, collection := range []string{"staff_assignments", "transfers", "listings", "waitlist", "promo_codes", "tickets", "scans", "payouts", "refunds"} {
+		unique := false
+		for _, index := range mongoIndexes[collection] {
+			if index.Options != nil && index.Options.Unique != nil && *index.Options.Unique {
+				unique = true
+			}
+		}
+		if !unique {
+			t.Errorf("%s has no unique index", collection)
+		}
+	}
+}
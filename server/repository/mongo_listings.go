@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoListingRepository struct {
+	collection *mongo.Collection
+}
+
+// openListingStatuses are the statuses in which a listing still holds its
+// ticket.
+var openListingStatuses = bson.A{"active", "reserved"}
+
+func (r *mongoListingRepository) Create(ctx context.Context, listing *models.Listing) error {
+	if listing.ID.IsZero() {
+		listing.ID = primitive.NewObjectID()
+	}
+
+	// Upsert on the ticket's open listing so a ticket is never listed twice
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"ticket_id": listing.TicketID, "status": bson.M{"$in": openListingStatuses}},
+		bson.M{"$setOnInsert": listing},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return duplicateKey(err)
+	}
+	if result.UpsertedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoListingRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Listing, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoListingRepository) FindOpenByTicket(ctx context.Context, ticketID primitive.ObjectID) (*models.Listing, error) {
+	return r.findOne(ctx, bson.M{"ticket_id": ticketID, "status": bson.M{"$in": openListingStatuses}})
+}
+
+func (r *mongoListingRepository) findOne(ctx context.Context, filter bson.M) (*models.Listing, error) {
+	var listing models.Listing
+	err := r.collection.FindOne(ctx, filter).Decode(&listing)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &listing, nil
+}
+
+func (r *mongoListingRepository) ListActiveByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.Listing, error) {
+	cursor, err := r.collection.Find(
+		ctx,
+		bson.M{"event_id": eventID, "status": "active"},
+		options.Find().SetSort(bson.D{{Key: "price", Value: 1}, {Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	listings := []models.Listing{}
+	if err := cursor.All(ctx, &listings); err != nil {
+		return nil, err
+	}
+	return listings, nil
+}
+
+func (r *mongoListingRepository) Reserve(ctx context.Context, id, buyerID, orderID primitive.ObjectID) error {
+	return r.transition(ctx, id, "active", bson.M{
+		"status":     "reserved",
+		"buyer_id":   buyerID,
+		"order_id":   orderID,
+		"updated_at": time.Now(),
+	})
+}
+
+func (r *mongoListingRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	return r.transition(ctx, id, from, bson.M{"status": to, "updated_at": time.Now()})
+}
+
+func (r *mongoListingRepository) transition(ctx context.Context, id primitive.ObjectID, from string, set bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": from}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
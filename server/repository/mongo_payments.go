@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoPaymentRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoPaymentRepository) Create(ctx context.Context, payment *models.Payment) error {
+	result, err := r.collection.InsertOne(ctx, payment)
+	if err != nil {
+		return err
+	}
+	payment.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoPaymentRepository) FindByOrder(ctx context.Context, orderID primitive.ObjectID) (*models.Payment, error) {
+	return r.findOne(ctx, bson.M{"order_id": orderID})
+}
+
+func (r *mongoPaymentRepository) FindByAuthorization(ctx context.Context, authorizationID string) (*models.Payment, error) {
+	return r.findOne(ctx, bson.M{"authorization_id": authorizationID})
+}
+
+func (r *mongoPaymentRepository) findOne(ctx context.Context, filter bson.M) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.collection.FindOne(ctx, filter).Decode(&payment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *mongoPaymentRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoPayoutRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoPayoutRepository) Create(ctx context.Context, payout *models.Payout) error {
+	if payout.ID.IsZero() {
+		payout.ID = primitive.NewObjectID()
+	}
+
+	// Upsert on the listing so a sale is never paid out twice
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"listing_id": payout.ListingID},
+		bson.M{"$setOnInsert": payout},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return duplicateKey(err)
+	}
+	if result.UpsertedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoPayoutRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Payout, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	payouts := []models.Payout{}
+	if err := cursor.All(ctx, &payouts); err != nil {
+		return nil, err
+	}
+	return payouts, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoPromoCodeRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoPromoCodeRepository) Create(ctx context.Context, promo *models.PromoCode) error {
+	if promo.ID.IsZero() {
+		promo.ID = primitive.NewObjectID()
+	}
+
+	// Upsert on event and code so a code is never defined twice
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"event_id": promo.EventID, "code": promo.Code},
+		bson.M{"$setOnInsert": promo},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return duplicateKey(err)
+	}
+	if result.UpsertedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoPromoCodeRepository) FindByCode(ctx context.Context, eventID primitive.ObjectID, code string) (*models.PromoCode, error) {
+	var promo models.PromoCode
+	err := r.collection.FindOne(ctx, bson.M{"event_id": eventID, "code": code}).Decode(&promo)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &promo, nil
+}
+
+func (r *mongoPromoCodeRepository) ListByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.PromoCode, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"event_id": eventID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	promos := []models.PromoCode{}
+	if err := cursor.All(ctx, &promos); err != nil {
+		return nil, err
+	}
+	return promos, nil
+}
+
+func (r *mongoPromoCodeRepository) Deactivate(ctx context.Context, eventID, id primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "event_id": eventID},
+		bson.M{"$set": bson.M{"active": false, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoPromoCodeRepository) Redeem(ctx context.Context, id primitive.ObjectID, quantity int) error {
+	// Conditional increment so concurrent bookings can never push uses
+	// past max_uses
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{
+			"_id":    id,
+			"active": true,
+			"$or": bson.A{
+				bson.M{"max_uses": 0},
+				bson.M{"$expr": bson.M{"$lte": bson.A{bson.M{"$add": bson.A{"$uses", quantity}}, "$max_uses"}}},
+			},
+		},
+		bson.M{"$inc": bson.M{"uses": quantity}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Err(); err == mongo.ErrNoDocuments {
+			return ErrNotFound
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoPromoCodeRepository) Release(ctx context.Context, id primitive.ObjectID, quantity int) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "uses": bson.M{"$gte": quantity}},
+		bson.M{"$inc": bson.M{"uses": -quantity}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoRefundRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoRefundRepository) Create(ctx context.Context, refund *models.Refund) error {
+	result, err := r.collection.InsertOne(ctx, refund)
+	if err != nil {
+		return duplicateKey(err)
+	}
+	refund.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoRefundRepository) FindByTicketAndOrder(ctx context.Context, ticketID, orderID primitive.ObjectID) (*models.Refund, error) {
+	var refund models.Refund
+	err := r.collection.FindOne(ctx, bson.M{"ticket_id": ticketID, "order_id": orderID}).Decode(&refund)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *mongoRefundRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
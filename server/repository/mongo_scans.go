@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoScanRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoScanRepository) Create(ctx context.Context, scan *models.Scan) error {
+	result, err := r.collection.InsertOne(ctx, scan)
+	if err != nil {
+		return duplicateKey(err)
+	}
+	scan.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoScanRepository) ExistsFromDevice(ctx context.Context, ticketID primitive.ObjectID, deviceID string, scannedAt time.Time) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"ticket_id":  ticketID,
+		"device_id":  deviceID,
+		"scanned_at": scannedAt,
+	}, options.Count().SetLimit(1))
+	return count > 0, err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoStaffRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoStaffRepository) Create(ctx context.Context, assignment *models.StaffAssignment) error {
+	if assignment.ID.IsZero() {
+		assignment.ID = primitive.NewObjectID()
+	}
+
+	// Upsert on event and email so inviting the same person twice cannot
+	// leave two assignments behind
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"event_id": assignment.EventID, "email": assignment.Email},
+		bson.M{"$setOnInsert": assignment},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return duplicateKey(err)
+	}
+	if result.UpsertedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoStaffRepository) ListByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.StaffAssignment, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"event_id": eventID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	assignments := []models.StaffAssignment{}
+	if err := cursor.All(ctx, &assignments); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+func (r *mongoStaffRepository) Delete(ctx context.Context, eventID, id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "event_id": eventID})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoStaffRepository) Accept(ctx context.Context, tokenHash, email string, userID primitive.ObjectID, acceptedAt time.Time) (*models.StaffAssignment, error) {
+	var assignment models.StaffAssignment
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"invite_token_hash": tokenHash, "email": email},
+		bson.M{
+			"$set":   bson.M{"user_id": userID, "accepted_at": acceptedAt},
+			"$unset": bson.M{"invite_token_hash": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&assignment)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+func (r *mongoStaffRepository) IsAssigned(ctx context.Context, eventID, userID primitive.ObjectID) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"event_id": eventID, "user_id": userID}, options.Count().SetLimit(1))
+	return count > 0, err
+}
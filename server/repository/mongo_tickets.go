@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type mongoTicketRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoTicketRepository) Create(ctx context.Context, ticket *models.Ticket) error {
+	result, err := r.collection.InsertOne(ctx, ticket)
+	if err != nil {
+		return duplicateKey(err)
+	}
+	ticket.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoTicketRepository) CreateMany(ctx context.Context, tickets []models.Ticket) error {
+	docs := make([]interface{}, len(tickets))
+	for i := range tickets {
+		if tickets[i].ID.IsZero() {
+			tickets[i].ID = primitive.NewObjectID()
+		}
+		docs[i] = tickets[i]
+	}
+	_, err := r.collection.InsertMany(ctx, docs)
+	return duplicateKey(err)
+}
+
+func (r *mongoTicketRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Ticket, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoTicketRepository) FindByQRCode(ctx context.Context, qrCode string) (*models.Ticket, error) {
+	return r.findOne(ctx, bson.M{"qr_code": qrCode})
+}
+
+func (r *mongoTicketRepository) findOne(ctx context.Context, filter bson.M) (*models.Ticket, error) {
+	var ticket models.Ticket
+	err := r.collection.FindOne(ctx, filter).Decode(&ticket)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (r *mongoTicketRepository) ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.TicketWithEvent, error) {
+	// Aggregation pipeline to join tickets with events
+	pipeline := []bson.M{
+		{"$match": bson.M{"user_id": userID}},
+		{"$lookup": bson.M{
+			"from":         "events",
+			"localField":   "event_id",
+			"foreignField": "_id",
+			"as":           "event",
+		}},
+		{"$unwind": "$event"},
+		{"$sort": bson.M{"created_at": -1}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		models.Ticket `bson:",inline"`
+		Event         models.Event `bson:"event"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	var result []models.TicketWithEvent
+	for _, doc := range docs {
+		result = append(result, models.NewTicketWithEvent(doc.Ticket, doc.Event))
+	}
+	return result, nil
+}
+
+func (r *mongoTicketRepository) ListByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.Ticket, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"event_id": eventID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tickets []models.Ticket
+	if err := cursor.All(ctx, &tickets); err != nil {
+		return nil, err
+	}
+	return tickets, nil
+}
+
+func (r *mongoTicketRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *mongoTicketRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "status": from},
+		bson.M{"$set": bson.M{"status": to, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		// Tell a missing ticket apart from one that changed under us
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoTicketRepository) RecordScan(ctx context.Context, ticket *models.Ticket, from string, scan models.TicketScan) error {
+	filter := bson.M{"_id": ticket.ID, "status": from, "scan_version": ticket.ScanVersion}
+	if ticket.ScanVersion == 0 {
+		// Tickets that were never scanned have no version stored
+		filter["scan_version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{
+		"$set": bson.M{
+			"status":     ticket.Status,
+			"scan_count": ticket.ScanCount,
+			"checked_in": ticket.CheckedIn,
+			"used_at":    ticket.UsedAt,
+			"used_gate":  ticket.UsedGate,
+			"updated_at": time.Now(),
+		},
+		"$push": bson.M{"scans": scan},
+		"$inc":  bson.M{"scan_version": 1},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, ticket.ID); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoTicketRepository) TransferOwnership(ctx context.Context, id, fromUserID, toUserID primitive.ObjectID, qrCode string, record models.TicketTransfer) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "user_id": fromUserID, "status": "active"},
+		bson.M{
+			"$set":  bson.M{"user_id": toUserID, "qr_code": qrCode, "updated_at": time.Now()},
+			"$push": bson.M{"transfers": record},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoTicketRepository) CountByUserAndEvent(ctx context.Context, userID, eventID primitive.ObjectID) (int, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"user_id":  userID,
+		"event_id": eventID,
+		"status":   bson.M{"$ne": "cancelled"},
+	})
+	return int(count), err
+}
+
+func (r *mongoTicketRepository) DeleteByOrder(ctx context.Context, orderID primitive.ObjectID) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"order_id": orderID})
+	return err
+}
+
+func (r *mongoTicketRepository) TransitionByOrder(ctx context.Context, orderID primitive.ObjectID, from, to string) (int, error) {
+	result, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"order_id": orderID, "status": from},
+		bson.M{"$set": bson.M{"status": to, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(result.ModifiedCount), nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoTransferRepository struct {
+	collection *mongo.Collection
+}
+
+func (r *mongoTransferRepository) Create(ctx context.Context, transfer *models.Transfer) error {
+	if transfer.ID.IsZero() {
+		transfer.ID = primitive.NewObjectID()
+	}
+
+	// Upsert on the ticket's pending transfer so two requests cannot both
+	// offer the same ticket
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"ticket_id": transfer.TicketID, "status": "pending"},
+		bson.M{"$setOnInsert": transfer},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return duplicateKey(err)
+	}
+	if result.UpsertedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoTransferRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Transfer, error) {
+	var transfer models.Transfer
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&transfer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *mongoTransferRepository) ListPendingByEmail(ctx context.Context, email string) ([]models.Transfer, error) {
+	cursor, err := r.collection.Find(
+		ctx,
+		bson.M{"to_email": email, "status": "pending"},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	transfers := []models.Transfer{}
+	if err := cursor.All(ctx, &transfers); err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+func (r *mongoTransferRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "status": from},
+		bson.M{"$set": bson.M{"status": to, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoTransferRepository) Accept(ctx context.Context, id, toUserID primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"_id": id, "status": "pending"},
+		bson.M{"$set": bson.M{"status": "accepted", "to_user_id": toUserID, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.FindByID(ctx, id); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
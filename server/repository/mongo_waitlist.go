@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoWaitlistRepository struct {
+	collection *mongo.Collection
+}
+
+// openWaitlistStatuses are the statuses in which an entry still holds the
+// user's place in the queue.
+var openWaitlistStatuses = bson.A{"waiting", "offered"}
+
+func (r *mongoWaitlistRepository) Create(ctx context.Context, entry *models.WaitlistEntry) error {
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+
+	// Upsert on the user's open entry so they can only queue once per event
+	result, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"event_id": entry.EventID, "user_id": entry.UserID, "status": bson.M{"$in": openWaitlistStatuses}},
+		bson.M{"$setOnInsert": entry},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return duplicateKey(err)
+	}
+	if result.UpsertedCount == 0 {
+		return ErrConflict
+	}
+	return nil
+}
+
+func (r *mongoWaitlistRepository) FindOpenByUser(ctx context.Context, eventID, userID primitive.ObjectID) (*models.WaitlistEntry, error) {
+	return r.findOne(ctx, bson.M{"event_id": eventID, "user_id": userID, "status": bson.M{"$in": openWaitlistStatuses}}, nil)
+}
+
+func (r *mongoWaitlistRepository) FindByHold(ctx context.Context, holdID primitive.ObjectID) (*models.WaitlistEntry, error) {
+	return r.findOne(ctx, bson.M{"hold_id": holdID}, nil)
+}
+
+func (r *mongoWaitlistRepository) NextWaiting(ctx context.Context, eventID, ticketTypeID primitive.ObjectID) (*models.WaitlistEntry, error) {
+	return r.findOne(
+		ctx,
+		bson.M{"event_id": eventID, "ticket_type_id": ticketTypeFilter(ticketTypeID), "status": "waiting"},
+		options.FindOne().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+}
+
+func (r *mongoWaitlistRepository) findOne(ctx context.Context, filter bson.M, opts *options.FindOneOptions) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	if opts == nil {
+		opts = options.FindOne()
+	}
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *mongoWaitlistRepository) CountWaitingBefore(ctx context.Context, entry *models.WaitlistEntry) (int, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"event_id":       entry.EventID,
+		"ticket_type_id": ticketTypeFilter(entry.TicketTypeID),
+		"status":         "waiting",
+		"created_at":     bson.M{"$lt": entry.CreatedAt},
+	})
+	return int(count), err
+}
+
+func (r *mongoWaitlistRepository) Offer(ctx context.Context, id, holdID primitive.ObjectID, expiresAt time.Time) error {
+	return r.transition(ctx, id, "waiting", bson.M{
+		"status":           "offered",
+		"hold_id":          holdID,
+		"offer_expires_at": expiresAt,
+		"updated_at":       time.Now(),
+	})
+}
+
+func (r *mongoWaitlistRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error {
+	return r.transition(ctx, id, from, bson.M{"status": to, "updated_at": time.Now()})
+}
+
+func (r *mongoWaitlistRepository) transition(ctx context.Context, id primitive.ObjectID, from string, set bson.M) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": from}, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.findOne(ctx, bson.M{"_id": id}, nil); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+// ticketTypeFilter matches entries for the ticket type; entries for events
+// without ticket types have no ticket_type_id stored.
+func ticketTypeFilter(ticketTypeID primitive.ObjectID) interface{} {
+	if ticketTypeID.IsZero() {
+		return bson.M{"$exists": false}
+	}
+	return ticketTypeID
+}
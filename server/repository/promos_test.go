@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"server/models"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testMongoStore connects to the database named by MONGO_TEST_URI and
+// builds a store on a scratch database that is dropped afterwards. Tests
+// that need it are skipped when the variable is unset.
+func testMongoStore(t *testing.T) *Store {
+	t.Helper()
+
+	uri := os.Getenv("MONGO_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := client.Database(fmt.Sprintf("event_ticketing_test_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		db.Drop(ctx)
+		client.Disconnect(ctx)
+	})
+
+	store, err := NewMongoStore(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func testPromoRedeemUnderConcurrency(t *testing.T, store *Store) {
+	ctx := context.Background()
+	const limit, redemptions = 5, 40
+
+	promo := models.PromoCode{Code: "SPRING", DiscountType: "percent", Amount: 10, MaxUses: limit, Active: true}
+	if err := store.Promos.Create(ctx, &promo); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	results := map[error]int{}
+	for i := 0; i < redemptions; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.Promos.Redeem(ctx, promo.ID, 1)
+			mu.Lock()
+			results[err]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if results[nil] != limit || results[ErrConflict] != redemptions-limit {
+		t.Errorf("results = %v, want %d redemptions and %d conflicts", results, limit, redemptions-limit)
+	}
+
+	stored, err := store.Promos.FindByCode(ctx, promo.EventID, promo.Code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Uses != limit {
+		t.Errorf("uses = %d, want %d", stored.Uses, limit)
+	}
+
+	// Released uses can be redeemed again, but no more than were taken
+	if err := store.Promos.Release(ctx, promo.ID, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Promos.Redeem(ctx, promo.ID, 3); err != ErrConflict {
+		t.Errorf("redeeming past the limit: err = %v, want ErrConflict", err)
+	}
+	if err := store.Promos.Redeem(ctx, promo.ID, 2); err != nil {
+		t.Errorf("redeeming released uses: %v", err)
+	}
+	if err := store.Promos.Release(ctx, promo.ID, limit+1); err != ErrNotFound {
+		t.Errorf("releasing more than were redeemed: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryPromoRedeemUnderConcurrency(t *testing.T) {
+	testPromoRedeemUnderConcurrency(t, NewMemoryStore())
+}
+
+func TestMongoPromoRedeemUnderConcurrency(t *testing.T) {
+	testPromoRedeemUnderConcurrency(t, testMongoStore(t))
+}
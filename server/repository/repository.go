@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"server/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	ErrSoldOut  = errors.New("no tickets available")
+	// ErrConflict is returned when a conditional update finds the document
+	// in a different state than expected.
+	ErrConflict = errors.New("conflict")
+)
+
+type EventRepository interface {
+	// Search returns a page of the events matching query, ordered by date
+	// and then ID.
+	Search(ctx context.Context, query models.EventQuery) (*models.EventPage, error)
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Event, error)
+	Create(ctx context.Context, event *models.Event) error
+	// Update persists the descriptive fields of an event. Inventory is only
+	// ever changed through ReserveTickets, ReleaseTickets and AdjustCapacity.
+	Update(ctx context.Context, event *models.Event) error
+	// Delete removes the event only while none of its seats are sold or
+	// held, returning ErrConflict otherwise.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// TransitionStatus moves an event from one lifecycle state to another
+	// only if it is still in the from state, returning ErrConflict
+	// otherwise. Events without a state are in the published state.
+	TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error
+	// ReserveTickets atomically takes quantity seats from available_tickets,
+	// returning ErrSoldOut when not enough are left. A non-zero ticketTypeID
+	// takes them from that ticket type as well as from the event.
+	ReserveTickets(ctx context.Context, id, ticketTypeID primitive.ObjectID, quantity int) error
+	ReleaseTickets(ctx context.Context, id, ticketTypeID primitive.ObjectID, quantity int) error
+	// AdjustCapacity shifts total_tickets and available_tickets by delta. A
+	// negative delta can only remove seats that are still available; when
+	// fewer are left it changes nothing and returns ErrConflict.
+	AdjustCapacity(ctx context.Context, id, ticketTypeID primitive.ObjectID, delta int) error
+	// AddTicketType appends a ticket type and grows the event's totals by
+	// its capacity.
+	AddTicketType(ctx context.Context, id primitive.ObjectID, ticketType models.TicketType) error
+	// UpdateTicketType changes the name and price of a ticket type.
+	UpdateTicketType(ctx context.Context, id primitive.ObjectID, ticketType models.TicketType) error
+}
+
+type TicketRepository interface {
+	Create(ctx context.Context, ticket *models.Ticket) error
+	CreateMany(ctx context.Context, tickets []models.Ticket) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Ticket, error)
+	FindByQRCode(ctx context.Context, qrCode string) (*models.Ticket, error)
+	// ListByUser returns the user's tickets joined with their events, newest first.
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.TicketWithEvent, error)
+	ListByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.Ticket, error)
+	UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error
+	// TransitionStatus moves a ticket from one status to another only if it
+	// is still in the from status, returning ErrConflict otherwise.
+	TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error
+	// RecordScan saves the scan state of ticket (status, scan count, check-in
+	// and latest admission) and appends scan to its log. ticket must have
+	// been derived from the stored ticket at ticket.ScanVersion while it was
+	// in the from status ("active", or "used" for a re-entry exit); if
+	// another scan was recorded since, or the status changed, it returns
+	// ErrConflict.
+	RecordScan(ctx context.Context, ticket *models.Ticket, from string, scan models.TicketScan) error
+	// TransferOwnership hands an active ticket from one user to another,
+	// replacing its QR code and adding record to its transfer history. It
+	// returns ErrConflict if the ticket is no longer active or owned by
+	// fromUserID.
+	TransferOwnership(ctx context.Context, id, fromUserID, toUserID primitive.ObjectID, qrCode string, record models.TicketTransfer) error
+	// CountByUserAndEvent counts the user's tickets for the event that have
+	// not been cancelled.
+	CountByUserAndEvent(ctx context.Context, userID, eventID primitive.ObjectID) (int, error)
+	DeleteByOrder(ctx context.Context, orderID primitive.ObjectID) error
+	// TransitionByOrder moves every ticket of the order that is in the from
+	// status to the to status and returns how many were changed.
+	TransitionByOrder(ctx context.Context, orderID primitive.ObjectID, from, to string) (int, error)
+}
+
+type OrderRepository interface {
+	Create(ctx context.Context, order *models.Order) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Order, error)
+	// FindPaidByListing returns the resale order that paid for the listing.
+	FindPaidByListing(ctx context.Context, listingID primitive.ObjectID) (*models.Order, error)
+	// TransitionStatus moves an order from one status to another only if it
+	// is still in the from status, returning ErrConflict otherwise.
+	TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error
+}
+
+type PaymentRepository interface {
+	Create(ctx context.Context, payment *models.Payment) error
+	FindByOrder(ctx context.Context, orderID primitive.ObjectID) (*models.Payment, error)
+	FindByAuthorization(ctx context.Context, authorizationID string) (*models.Payment, error)
+	UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error
+}
+
+type HoldRepository interface {
+	Create(ctx context.Context, hold *models.Hold) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Hold, error)
+	// TransitionStatus moves a hold from one status to another only if it
+	// is still in the from status, returning ErrConflict otherwise.
+	TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error
+	// ListExpired returns the active holds whose expiry is at or before now.
+	ListExpired(ctx context.Context, now time.Time) ([]models.Hold, error)
+	ListActiveByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.Hold, error)
+	// SumActiveByUserAndEvent totals the seats the user currently holds.
+	SumActiveByUserAndEvent(ctx context.Context, userID, eventID primitive.ObjectID) (int, error)
+}
+
+type RefundRepository interface {
+	// Create returns ErrConflict when the ticket has already been refunded
+	// for the order.
+	Create(ctx context.Context, refund *models.Refund) error
+	FindByTicketAndOrder(ctx context.Context, ticketID, orderID primitive.ObjectID) (*models.Refund, error)
+	UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error
+}
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	// ListByUser returns the user's notifications, newest first.
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Notification, error)
+}
+
+type PayoutRepository interface {
+	// Create returns ErrConflict when the listing has already been paid out.
+	Create(ctx context.Context, payout *models.Payout) error
+	// ListByUser returns the seller's payouts, newest first.
+	ListByUser(ctx context.Context, userID primitive.ObjectID) ([]models.Payout, error)
+}
+
+type ScanRepository interface {
+	// Create returns ErrConflict when the device has already uploaded the
+	// scan.
+	Create(ctx context.Context, scan *models.Scan) error
+	// ExistsFromDevice reports whether the device has already uploaded a
+	// scan of the ticket made at scannedAt.
+	ExistsFromDevice(ctx context.Context, ticketID primitive.ObjectID, deviceID string, scannedAt time.Time) (bool, error)
+}
+
+type StaffRepository interface {
+	// Create returns ErrConflict when the email is already assigned to the
+	// event.
+	Create(ctx context.Context, assignment *models.StaffAssignment) error
+	ListByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.StaffAssignment, error)
+	Delete(ctx context.Context, eventID, id primitive.ObjectID) error
+	// Accept binds the pending invite with the token hash to the account,
+	// which must have the invited email, and clears the token so it cannot
+	// be used again. It returns ErrNotFound when no such invite is pending.
+	Accept(ctx context.Context, tokenHash, email string, userID primitive.ObjectID, acceptedAt time.Time) (*models.StaffAssignment, error)
+	// IsAssigned reports whether the account has accepted an invite to the
+	// event.
+	IsAssigned(ctx context.Context, eventID, userID primitive.ObjectID) (bool, error)
+}
+
+type TransferRepository interface {
+	// Create returns ErrConflict when the ticket already has a pending
+	// transfer.
+	Create(ctx context.Context, transfer *models.Transfer) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Transfer, error)
+	// ListPendingByEmail returns the transfers waiting for the recipient,
+	// newest first.
+	ListPendingByEmail(ctx context.Context, email string) ([]models.Transfer, error)
+	// TransitionStatus moves a transfer from one status to another only if
+	// it is still in the from status, returning ErrConflict otherwise.
+	TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error
+	// Accept moves a pending transfer to accepted and records who accepted
+	// it, returning ErrConflict if it is no longer pending.
+	Accept(ctx context.Context, id, toUserID primitive.ObjectID) error
+}
+
+type ListingRepository interface {
+	// Create returns ErrConflict when the ticket is already listed.
+	Create(ctx context.Context, listing *models.Listing) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Listing, error)
+	// FindOpenByTicket returns the ticket's active or reserved listing.
+	FindOpenByTicket(ctx context.Context, ticketID primitive.ObjectID) (*models.Listing, error)
+	// ListActiveByEvent returns the listings buyers can pick from, cheapest
+	// first.
+	ListActiveByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.Listing, error)
+	// Reserve moves an active listing to reserved for the buyer's order,
+	// returning ErrConflict if it is no longer active.
+	Reserve(ctx context.Context, id, buyerID, orderID primitive.ObjectID) error
+	// TransitionStatus moves a listing from one status to another only if it
+	// is still in the from status, returning ErrConflict otherwise.
+	TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error
+}
+
+type WaitlistRepository interface {
+	// Create returns ErrConflict when the user is already waiting for, or
+	// has an open offer for, the event.
+	Create(ctx context.Context, entry *models.WaitlistEntry) error
+	// FindOpenByUser returns the user's waiting or offered entry.
+	FindOpenByUser(ctx context.Context, eventID, userID primitive.ObjectID) (*models.WaitlistEntry, error)
+	// FindByHold returns the entry that was offered the hold.
+	FindByHold(ctx context.Context, holdID primitive.ObjectID) (*models.WaitlistEntry, error)
+	// NextWaiting returns the longest waiting entry for the ticket type.
+	NextWaiting(ctx context.Context, eventID, ticketTypeID primitive.ObjectID) (*models.WaitlistEntry, error)
+	// CountWaitingBefore counts the entries queued ahead of entry.
+	CountWaitingBefore(ctx context.Context, entry *models.WaitlistEntry) (int, error)
+	// Offer moves a waiting entry to offered with the hold made for it,
+	// returning ErrConflict if it is no longer waiting.
+	Offer(ctx context.Context, id, holdID primitive.ObjectID, expiresAt time.Time) error
+	// TransitionStatus moves an entry from one status to another only if it
+	// is still in the from status, returning ErrConflict otherwise.
+	TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to string) error
+}
+
+type PromoCodeRepository interface {
+	// Create returns ErrConflict when the event already has the code.
+	Create(ctx context.Context, promo *models.PromoCode) error
+	FindByCode(ctx context.Context, eventID primitive.ObjectID, code string) (*models.PromoCode, error)
+	ListByEvent(ctx context.Context, eventID primitive.ObjectID) ([]models.PromoCode, error)
+	Deactivate(ctx context.Context, eventID, id primitive.ObjectID) error
+	// Redeem atomically counts quantity uses of an active code, returning
+	// ErrConflict when that would exceed its usage limit.
+	Redeem(ctx context.Context, id primitive.ObjectID, quantity int) error
+	// Release gives back uses counted by Redeem.
+	Release(ctx context.Context, id primitive.ObjectID, quantity int) error
+}
+
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+}
+
+// Store bundles the repositories the controllers depend on.
+type Store struct {
+	Events        EventRepository
+	Tickets       TicketRepository
+	Orders        OrderRepository
+	Holds         HoldRepository
+	Payments      PaymentRepository
+	Refunds       RefundRepository
+	Scans         ScanRepository
+	Staff         StaffRepository
+	Transfers     TransferRepository
+	Listings      ListingRepository
+	Waitlist      WaitlistRepository
+	Promos        PromoCodeRepository
+	Users         UserRepository
+	Notifications NotificationRepository
+	Payouts       PayoutRepository
+}
+
+// NewMongoStore builds the store on db, creating the indexes it relies on.
+func NewMongoStore(ctx context.Context, db *mongo.Database) (*Store, error) {
+	if err := ensureMongoIndexes(ctx, db); err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		Events:        &mongoEventRepository{collection: db.Collection("events")},
+		Tickets:       &mongoTicketRepository{collection: db.Collection("tickets")},
+		Orders:        &mongoOrderRepository{collection: db.Collection("orders")},
+		Holds:         &mongoHoldRepository{collection: db.Collection("holds")},
+		Payments:      &mongoPaymentRepository{collection: db.Collection("payments")},
+		Refunds:       &mongoRefundRepository{collection: db.Collection("refunds")},
+		Scans:         &mongoScanRepository{collection: db.Collection("scans")},
+		Staff:         &mongoStaffRepository{collection: db.Collection("staff_assignments")},
+		Transfers:     &mongoTransferRepository{collection: db.Collection("transfers")},
+		Listings:      &mongoListingRepository{collection: db.Collection("listings")},
+		Waitlist:      &mongoWaitlistRepository{collection: db.Collection("waitlist")},
+		Promos:        &mongoPromoCodeRepository{collection: db.Collection("promo_codes")},
+		Users:         &mongoUserRepository{collection: db.Collection("users")},
+		Notifications: &mongoNotificationRepository{collection: db.Collection("notifications")},
+		Payouts:       &mongoPayoutRepository{collection: db.Collection("payouts")},
+	}, nil
+}
@@ -2,12 +2,13 @@ package routes
 
 import (
 	"server/controllers"
+	"server/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupAuthRoutes(r *gin.Engine) {
-	authController := &controllers.AuthController{}
+func SetupAuthRoutes(r *gin.Engine, store *repository.Store) {
+	authController := controllers.NewAuthController(store)
 	auth := r.Group("/auth")
 	{
 		auth.POST("/register", authController.Register)
@@ -0,0 +1,98 @@
+package routes_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"server/payments"
+	"testing"
+)
+
+// pdfPage matches a page object, but not the page tree.
+var pdfPage = regexp.MustCompile(`/Type /Page[^s]`)
+
+// pdfPages checks the response is a PDF download and counts its pages.
+func pdfPages(t *testing.T, w *httptest.ResponseRecorder, filename string) int {
+	t.Helper()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "application/pdf" || !bytes.HasPrefix(w.Body.Bytes(), []byte("%PDF-")) {
+		t.Fatalf("response is not a PDF: %q", w.Header().Get("Content-Type"))
+	}
+	if want := `attachment; filename="` + filename + `"`; w.Header().Get("Content-Disposition") != want {
+		t.Errorf("Content-Disposition = %q, want %q", w.Header().Get("Content-Disposition"), want)
+	}
+	return len(pdfPage.FindAll(w.Body.Bytes(), -1))
+}
+
+func TestTicketPDF(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	other := api.register("other", "user")
+	eventID := api.createEvent(organizer, map[string]any{"title": "Café Concert"})
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	ticketID := bookedTickets(out)[0]["id"].(string)
+
+	w := api.fetch("/tickets/"+ticketID+"/pdf", buyer)
+	if pages := pdfPages(t, w, "ticket-"+ticketID+".pdf"); pages != 1 {
+		t.Errorf("ticket PDF has %d pages, want 1", pages)
+	}
+
+	api.mustDo(http.StatusUnauthorized, "GET", "/tickets/"+ticketID+"/pdf", "", nil)
+	api.mustDo(http.StatusNotFound, "GET", "/tickets/"+ticketID+"/pdf", other, nil)
+
+	api.mustDo(http.StatusOK, "POST", "/tickets/"+ticketID+"/cancel", buyer, nil)
+	api.mustDo(http.StatusBadRequest, "GET", "/tickets/"+ticketID+"/pdf", buyer, nil)
+}
+
+func TestOrderPDF(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	friend := api.register("friend", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"quantity": 4, "payment_token": "tok_ok"})
+	orderID := out["order_id"].(string)
+	tickets := bookedTickets(out)
+
+	w := api.fetch("/tickets/orders/"+orderID+"/pdf", buyer)
+	if pages := pdfPages(t, w, "order-"+orderID+".pdf"); pages != 4 {
+		t.Errorf("order PDF has %d pages, want 4", pages)
+	}
+
+	// Cancelled tickets and tickets given away are left out; a used one
+	// stays in as a keepsake
+	api.mustDo(http.StatusOK, "POST", "/tickets/"+tickets[0]["id"].(string)+"/cancel", buyer, nil)
+	api.give(tickets[1]["id"].(string), buyer, friend, "friend@example.com")
+	api.mustDo(http.StatusOK, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": tickets[2]["qr_code"]})
+	w = api.fetch("/tickets/orders/"+orderID+"/pdf", buyer)
+	if pages := pdfPages(t, w, "order-"+orderID+".pdf"); pages != 2 {
+		t.Errorf("order PDF has %d pages after changes, want 2", pages)
+	}
+
+	// The order stays the buyer's; the friend prints their ticket on its own
+	api.mustDo(http.StatusNotFound, "GET", "/tickets/orders/"+orderID+"/pdf", friend, nil)
+	w = api.fetch("/tickets/"+tickets[1]["id"].(string)+"/pdf", friend)
+	if pages := pdfPages(t, w, "ticket-"+tickets[1]["id"].(string)+".pdf"); pages != 1 {
+		t.Errorf("transferred ticket PDF has %d pages, want 1", pages)
+	}
+
+	api.mustDo(http.StatusOK, "POST", "/tickets/"+tickets[3]["id"].(string)+"/cancel", buyer, nil)
+	w = api.fetch("/tickets/orders/"+orderID+"/pdf", buyer)
+	if pages := pdfPages(t, w, "order-"+orderID+".pdf"); pages != 1 {
+		t.Errorf("order PDF has %d pages with one ticket left, want 1", pages)
+	}
+
+	out = api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": payments.FakeTokenDelayed})
+	out = api.mustDo(http.StatusBadRequest, "GET", "/tickets/orders/"+out["order_id"].(string)+"/pdf", buyer, nil)
+	if out["error"] != "Order has not been paid" {
+		t.Errorf("pending order: error = %v", out["error"])
+	}
+	api.mustDo(http.StatusBadRequest, "GET", "/tickets/orders/not-an-id/pdf", buyer, nil)
+}
@@ -3,21 +3,37 @@ package routes
 import (
 	"server/controllers"
 	"server/middleware"
+	"server/payments"
+	"server/repository"
+	"server/waitlist"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupEventRoutes(r *gin.Engine) {
-	eventController := &controllers.EventController{}
+func SetupEventRoutes(r *gin.Engine, store *repository.Store, paymentService *payments.Service, offers *waitlist.Service) {
+	eventController := controllers.NewEventController(store, paymentService, offers)
+	waitlistController := controllers.NewWaitlistController(store, offers)
 	events := r.Group("/events")
 	{
-		// Public routes
-		events.GET("", eventController.GetEvents)
-		events.GET("/:id", eventController.GetEvent)
+		// Public routes, drafts are only shown to their organizer
+		events.GET("", middleware.OptionalAuth(), eventController.GetEvents)
+		events.GET("/:id", middleware.OptionalAuth(), eventController.GetEvent)
+
+		// Protected routes
+		events.POST("/:id/waitlist", middleware.AuthRequired(), waitlistController.JoinWaitlist)
+		events.GET("/:id/waitlist", middleware.AuthRequired(), waitlistController.GetWaitlistEntry)
+		events.DELETE("/:id/waitlist", middleware.AuthRequired(), waitlistController.LeaveWaitlist)
 
 		// Protected routes (organizer only)
 		events.POST("", middleware.AuthRequired(), middleware.RoleRequired("organizer"), eventController.CreateEvent)
 		events.PUT("/:id", middleware.AuthRequired(), middleware.RoleRequired("organizer"), eventController.UpdateEvent)
+		events.PUT("/:id/status", middleware.AuthRequired(), middleware.RoleRequired("organizer"), eventController.UpdateEventStatus)
 		events.DELETE("/:id", middleware.AuthRequired(), middleware.RoleRequired("organizer"), eventController.DeleteEvent)
+		events.POST("/:id/staff", middleware.AuthRequired(), middleware.RoleRequired("organizer"), eventController.InviteStaff)
+		events.GET("/:id/staff", middleware.AuthRequired(), middleware.RoleRequired("organizer"), eventController.ListStaff)
+		events.DELETE("/:id/staff/:staffId", middleware.AuthRequired(), middleware.RoleRequired("organizer"), eventController.RemoveStaff)
+		events.POST("/:id/promo-codes", middleware.AuthRequired(), middleware.RoleRequired("organizer"), eventController.CreatePromoCode)
+		events.GET("/:id/promo-codes", middleware.AuthRequired(), middleware.RoleRequired("organizer"), eventController.ListPromoCodes)
+		events.DELETE("/:id/promo-codes/:promoId", middleware.AuthRequired(), middleware.RoleRequired("organizer"), eventController.DeactivatePromoCode)
 	}
 }
@@ -0,0 +1,458 @@
+package routes_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"server/jobs"
+	"server/models"
+	"server/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNegativePurchaseLimitsRejected(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+
+	body := map[string]any{
+		"title":         "Concert",
+		"date":          "2035-06-01T20:00:00Z",
+		"location":      "Main Hall",
+		"price":         25,
+		"total_tickets": 10,
+		"max_per_user":  -1,
+	}
+	api.mustDo(http.StatusBadRequest, "POST", "/events", organizer, body)
+
+	eventID := api.createEvent(organizer, map[string]any{"max_per_order": 2})
+	api.mustDo(http.StatusBadRequest, "PUT", "/events/"+eventID, organizer, map[string]any{"max_per_order": -2})
+	api.mustDo(http.StatusOK, "PUT", "/events/"+eventID, organizer, map[string]any{"max_per_order": 0})
+}
+
+// refundsByTicket indexes the refunds of a cancellation response by ticket.
+func refundsByTicket(out map[string]any) map[string]map[string]any {
+	refunds := map[string]map[string]any{}
+	for _, refund := range out["refunds"].([]any) {
+		refund := refund.(map[string]any)
+		refunds[refund["ticket_id"].(string)] = refund
+	}
+	return refunds
+}
+
+// give transfers the ticket to the recipient, who accepts it.
+func (api *testAPI) give(ticketID, owner, recipient, email string) {
+	api.t.Helper()
+
+	transfer := api.mustDo(http.StatusCreated, "POST", "/tickets/"+ticketID+"/transfer", owner, map[string]any{"email": email})
+	api.mustDo(http.StatusOK, "POST", "/tickets/transfers/"+transfer["id"].(string)+"/accept", recipient, nil)
+}
+
+func TestEventCancellationRefundsLastPayer(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	seller := api.register("seller", "user")
+	buyer := api.register("buyer", "user")
+	friend := api.register("friend", "user")
+	eventID := api.createEvent(organizer, map[string]any{"resale_enabled": true, "resale_fee": 2})
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, seller, map[string]any{"quantity": 2, "payment_token": "tok_ok"})
+	tickets := bookedTickets(out)
+	resold, gifted := tickets[0]["id"].(string), tickets[1]["id"].(string)
+	originalOrder := out["order_id"].(string)
+
+	// One ticket is resold and then given away by its buyer, the other is
+	// given away by the original buyer
+	listing := api.mustDo(http.StatusCreated, "POST", "/tickets/"+resold+"/resale", seller, map[string]any{"price": 20})
+	purchase := api.mustDo(http.StatusOK, "POST", "/tickets/resale/"+listing["id"].(string)+"/buy", buyer, map[string]any{"payment_token": "tok_ok"})
+	resaleOrder := purchase["order"].(map[string]any)
+	api.give(resold, buyer, friend, "friend@example.com")
+	api.give(gifted, seller, friend, "friend@example.com")
+
+	out = api.mustDo(http.StatusOK, "DELETE", "/events/"+eventID, organizer, nil)
+	refunds := refundsByTicket(out)
+	if len(refunds) != 2 {
+		t.Fatalf("got refunds %v, want one per ticket", out["refunds"])
+	}
+
+	// The resale buyer gets back what they paid; the seller was paid out
+	refund := refunds[resold]
+	if refund["user_id"] != resaleOrder["user_id"] || refund["order_id"] != resaleOrder["id"] || refund["amount"] != resaleOrder["total_price"] {
+		t.Errorf("resold ticket refund = %v, want the resale order %v refunded", refund, resaleOrder)
+	}
+	refund = refunds[gifted]
+	if refund["user_id"] != listing["seller_id"] || refund["order_id"] != originalOrder || refund["amount"] != 25.0 {
+		t.Errorf("gifted ticket refund = %v, want the original order refunded to the seller", refund)
+	}
+
+	// The holder is not told they were refunded
+	notifications := api.mustDo(http.StatusOK, "GET", "/notifications", friend, nil)["notifications"].([]any)
+	if len(notifications) != 1 {
+		t.Fatalf("holder got %d notifications, want 1", len(notifications))
+	}
+	if message := notifications[0].(map[string]any)["message"].(string); strings.Contains(message, "has been refunded") {
+		t.Errorf("holder notified %q", message)
+	}
+}
+
+// flakyRefunds fails the next failures refunds it is asked to record.
+type flakyRefunds struct {
+	repository.RefundRepository
+	failures int
+}
+
+func (r *flakyRefunds) Create(ctx context.Context, refund *models.Refund) error {
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("connection reset")
+	}
+	return r.RefundRepository.Create(ctx, refund)
+}
+
+func TestEventCancellationRetriesMissingRefunds(t *testing.T) {
+	store := repository.NewMemoryStore()
+	refunds := &flakyRefunds{RefundRepository: store.Refunds}
+	store.Refunds = refunds
+	api := newTestAPIWithStore(t, store)
+	organizer := api.register("organizer", "organizer")
+	user := api.register("user", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, user, map[string]any{"quantity": 2, "payment_token": "tok_ok"})
+
+	// Every ticket is cancelled even though a refund could not be recorded
+	refunds.failures = 1
+	api.mustDo(http.StatusInternalServerError, "DELETE", "/events/"+eventID, organizer, nil)
+	if got := api.ticketStatuses(eventID); got["cancelled"] != 2 {
+		t.Fatalf("ticket statuses = %v, want both cancelled", got)
+	}
+
+	// Running it again refunds the ticket that was missed, and only that one
+	out := api.mustDo(http.StatusOK, "DELETE", "/events/"+eventID, organizer, nil)
+	first := refundsByTicket(out)
+	if len(first) != 2 {
+		t.Fatalf("got refunds %v, want one per ticket", out["refunds"])
+	}
+	out = api.mustDo(http.StatusOK, "DELETE", "/events/"+eventID, organizer, nil)
+	for ticketID, refund := range refundsByTicket(out) {
+		if refund["id"] != first[ticketID]["id"] {
+			t.Errorf("ticket %s refunded again: %v, then %v", ticketID, first[ticketID], refund)
+		}
+	}
+	if out["cancelled_tickets"] != 0.0 {
+		t.Errorf("cancelled_tickets = %v on a rerun, want 0", out["cancelled_tickets"])
+	}
+}
+
+func TestDeleteEventReleasesHolds(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	user := api.register("user", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	hold := api.mustDo(http.StatusCreated, "POST", "/tickets/hold/"+eventID, user, map[string]any{"quantity": 2})
+	api.mustDo(http.StatusOK, "DELETE", "/events/"+eventID, organizer, nil)
+	api.mustDo(http.StatusNotFound, "GET", "/events/"+eventID, "", nil)
+
+	// The hold no longer refers to seats that can be bought or expired
+	api.mustDo(http.StatusConflict, "POST", "/tickets/holds/"+hold["id"].(string)+"/checkout", user, map[string]any{"payment_token": "tok_ok"})
+	api.offers.Now = func() time.Time { return time.Now().Add(24 * time.Hour) }
+	expirer := jobs.NewHoldExpirer(api.store, api.offers, time.Minute)
+	if _, err := expirer.ExpireDue(context.Background()); err != nil {
+		t.Errorf("expiring holds after the delete: %v", err)
+	}
+}
+
+func TestDeleteEventRacingBooking(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	eventID := api.createEvent(organizer, nil)
+
+	// A booking that took its seats before sales closed, still issuing tickets
+	objectID, _ := primitive.ObjectIDFromHex(eventID)
+	if err := api.store.Events.ReserveTickets(context.Background(), objectID, primitive.NilObjectID, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	out := api.mustDo(http.StatusOK, "DELETE", "/events/"+eventID, organizer, nil)
+	if !strings.Contains(out["message"].(string), "cancelled instead") {
+		t.Errorf("message = %q, want the event cancelled instead", out["message"])
+	}
+	event := api.mustDo(http.StatusOK, "GET", "/events/"+eventID, organizer, nil)
+	if event["status"] != "cancelled" {
+		t.Errorf("event status = %v, want cancelled", event["status"])
+	}
+}
+
+func TestUpdateCapacityMustBePositive(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	eventID := api.createEvent(organizer, nil)
+
+	for _, total := range []int{0, -5} {
+		api.mustDo(http.StatusBadRequest, "PUT", "/events/"+eventID, organizer, map[string]any{"total_tickets": total})
+	}
+	if got := api.availableTickets(eventID); got != 10 {
+		t.Errorf("available tickets = %d, want 10", got)
+	}
+}
+
+// failingEventUpdates fails every update of an event's details.
+type failingEventUpdates struct {
+	repository.EventRepository
+}
+
+func (r failingEventUpdates) Update(ctx context.Context, event *models.Event) error {
+	return errors.New("connection reset")
+}
+
+func TestFailedUpdateRestoresCapacity(t *testing.T) {
+	store := repository.NewMemoryStore()
+	store.Events = failingEventUpdates{store.Events}
+	api := newTestAPIWithStore(t, store)
+	organizer := api.register("organizer", "organizer")
+	eventID := api.createEvent(organizer, nil)
+
+	for _, total := range []int{15, 4} {
+		api.mustDo(http.StatusInternalServerError, "PUT", "/events/"+eventID, organizer, map[string]any{"total_tickets": total})
+		event := api.mustDo(http.StatusOK, "GET", "/events/"+eventID, "", nil)
+		if event["total_tickets"] != 10.0 || event["available_tickets"] != 10.0 {
+			t.Errorf("after a failed update to %d: total %v, available %v, want 10 and 10",
+				total, event["total_tickets"], event["available_tickets"])
+		}
+	}
+}
+
+// listedTitles returns the titles of a page of GET /events.
+func (api *testAPI) listedTitles(path, token string) []string {
+	api.t.Helper()
+
+	var titles []string
+	for _, event := range api.mustDo(http.StatusOK, "GET", path, token, nil)["events"].([]any) {
+		titles = append(titles, event.(map[string]any)["title"].(string))
+	}
+	return titles
+}
+
+func TestListingKeepsRunningEvents(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+
+	// A festival that started yesterday and runs for another two days
+	started := time.Now().Add(-24 * time.Hour).UTC()
+	api.createEvent(organizer, map[string]any{
+		"title":    "Festival",
+		"date":     started.Format(time.RFC3339),
+		"end_date": started.Add(72 * time.Hour).Format(time.RFC3339),
+	})
+	api.createEvent(organizer, map[string]any{"title": "Yesterday", "date": started.Format(time.RFC3339)})
+
+	titles := api.listedTitles("/events", "")
+	if len(titles) != 1 || titles[0] != "Festival" {
+		t.Errorf("listed %v, want only the running festival", titles)
+	}
+}
+
+// failingEventDeletes fails every delete of an event.
+type failingEventDeletes struct {
+	repository.EventRepository
+}
+
+func (r failingEventDeletes) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return errors.New("connection reset")
+}
+
+func TestFailedDeleteRestoresStatus(t *testing.T) {
+	store := repository.NewMemoryStore()
+	store.Events = failingEventDeletes{store.Events}
+	api := newTestAPIWithStore(t, store)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	api.mustDo(http.StatusInternalServerError, "DELETE", "/events/"+eventID, organizer, nil)
+
+	// The event was not deleted, so it is still on sale
+	event := api.mustDo(http.StatusOK, "GET", "/events/"+eventID, "", nil)
+	if event["status"] != "published" {
+		t.Errorf("event status = %v, want published", event["status"])
+	}
+	api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+}
+
+func TestEventPagesShareDates(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+
+	// Pages break in the middle of runs of events on the same date
+	dates := []string{"2035-06-01T20:00:00Z", "2035-06-02T20:00:00Z", "2035-06-01T20:00:00Z", "2035-06-02T20:00:00Z", "2035-06-01T20:00:00Z", "2035-06-02T20:00:00Z", "2035-06-03T20:00:00Z"}
+	for _, date := range dates {
+		api.createEvent(organizer, map[string]any{"date": date})
+	}
+
+	seen := map[string]bool{}
+	var listed []string
+	path := "/events?limit=2"
+	for pages := 0; ; pages++ {
+		if pages > len(dates) {
+			t.Fatal("listing did not end")
+		}
+		out := api.mustDo(http.StatusOK, "GET", path, "", nil)
+		if out["total"] != float64(len(dates)) {
+			t.Errorf("page %d: total = %v, want %d", pages, out["total"], len(dates))
+		}
+		for _, event := range out["events"].([]any) {
+			event := event.(map[string]any)
+			id := event["id"].(string)
+			if seen[id] {
+				t.Errorf("event %s listed twice", id)
+			}
+			seen[id] = true
+			listed = append(listed, event["date"].(string))
+		}
+		if out["next_cursor"] == nil {
+			break
+		}
+		path = "/events?limit=2&cursor=" + out["next_cursor"].(string)
+	}
+
+	if len(seen) != len(dates) {
+		t.Errorf("listed %d events, want %d", len(seen), len(dates))
+	}
+	for i := 1; i < len(listed); i++ {
+		if listed[i] < listed[i-1] {
+			t.Errorf("events out of date order: %v", listed)
+			break
+		}
+	}
+
+	out := api.mustDo(http.StatusBadRequest, "GET", "/events?cursor=not-a-cursor", "", nil)
+	if out["error"] != "Invalid cursor" {
+		t.Errorf("error = %v, want Invalid cursor", out["error"])
+	}
+}
+
+func TestEventLifecycle(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	other := api.register("other", "organizer")
+	buyer := api.register("buyer", "user")
+
+	eventID := api.createEvent(organizer, map[string]any{"title": "Draft", "status": "draft"})
+	event := api.mustDo(http.StatusOK, "GET", "/events/"+eventID, organizer, nil)
+	if event["status"] != "draft" || event["sales_status"] != "upcoming" {
+		t.Errorf("new event is %v with sales %v, want an upcoming draft", event["status"], event["sales_status"])
+	}
+	organizerID := event["organizer_id"].(string)
+
+	// Drafts are hidden from everyone but their organizer
+	api.mustDo(http.StatusNotFound, "GET", "/events/"+eventID, "", nil)
+	api.mustDo(http.StatusNotFound, "GET", "/events/"+eventID, other, nil)
+	if titles := api.listedTitles("/events", ""); len(titles) != 0 {
+		t.Errorf("public listing shows %v", titles)
+	}
+	if titles := api.listedTitles("/events?organizer_id="+organizerID, other); len(titles) != 0 {
+		t.Errorf("another organizer sees %v", titles)
+	}
+	if titles := api.listedTitles("/events?organizer_id="+organizerID, organizer); len(titles) != 1 || titles[0] != "Draft" {
+		t.Errorf("organizer sees %v, want their draft", titles)
+	}
+	out := api.mustDo(http.StatusBadRequest, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	if out["error"] != "Event has not been published" {
+		t.Errorf("booking a draft: error = %v", out["error"])
+	}
+
+	// Only the organizer may move the event along
+	api.mustDo(http.StatusNotFound, "PUT", "/events/"+eventID+"/status", other, map[string]any{"status": "published"})
+	api.mustDo(http.StatusBadRequest, "PUT", "/events/"+eventID+"/status", organizer, map[string]any{"status": "postponed"})
+	for _, status := range []string{"sales_paused", "completed", "draft"} {
+		api.mustDo(http.StatusConflict, "PUT", "/events/"+eventID+"/status", organizer, map[string]any{"status": status})
+	}
+
+	event = api.mustDo(http.StatusOK, "PUT", "/events/"+eventID+"/status", organizer, map[string]any{"status": "published"})
+	if event["status"] != "published" {
+		t.Errorf("status = %v, want published", event["status"])
+	}
+	if titles := api.listedTitles("/events", ""); len(titles) != 1 {
+		t.Errorf("public listing shows %v, want the published event", titles)
+	}
+	out = api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	ticketID := bookedTickets(out)[0]["id"].(string)
+
+	api.mustDo(http.StatusConflict, "PUT", "/events/"+eventID+"/status", organizer, map[string]any{"status": "draft"})
+	// The event has yet to take place
+	api.mustDo(http.StatusConflict, "PUT", "/events/"+eventID+"/status", organizer, map[string]any{"status": "completed"})
+
+	api.mustDo(http.StatusOK, "PUT", "/events/"+eventID+"/status", organizer, map[string]any{"status": "cancelled"})
+	event = api.mustDo(http.StatusOK, "GET", "/events/"+eventID, "", nil)
+	if event["status"] != "cancelled" || event["sales_status"] != "closed" {
+		t.Errorf("cancelled event is %v with sales %v", event["status"], event["sales_status"])
+	}
+	objectID, _ := primitive.ObjectIDFromHex(ticketID)
+	ticket, err := api.store.Tickets.FindByID(context.Background(), objectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.Status != "cancelled" {
+		t.Errorf("ticket status = %s, want cancelled", ticket.Status)
+	}
+
+	// Cancelled events are final
+	for _, status := range []string{"published", "sales_paused", "draft", "completed", "cancelled"} {
+		api.mustDo(http.StatusConflict, "PUT", "/events/"+eventID+"/status", organizer, map[string]any{"status": status})
+	}
+}
+
+func TestSalesWindow(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	now := time.Now().UTC()
+	at := func(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+
+	// Windows must open before they close, and close by the event
+	api.mustDo(http.StatusBadRequest, "POST", "/events", organizer, map[string]any{
+		"title": "Concert", "date": "2035-06-01T20:00:00Z", "location": "Main Hall", "price": 25, "total_tickets": 10,
+		"sales_start": at(2 * time.Hour), "sales_end": at(time.Hour),
+	})
+	api.mustDo(http.StatusBadRequest, "POST", "/events", organizer, map[string]any{
+		"title": "Concert", "date": "2035-06-01T20:00:00Z", "location": "Main Hall", "price": 25, "total_tickets": 10,
+		"sales_end": "2035-06-02T20:00:00Z",
+	})
+
+	upcoming := api.createEvent(organizer, map[string]any{"title": "Upcoming", "sales_start": at(time.Hour)})
+	open := api.createEvent(organizer, map[string]any{"title": "Open", "sales_start": at(-time.Hour), "sales_end": at(time.Hour)})
+	ended := api.createEvent(organizer, map[string]any{"title": "Ended", "sales_start": at(-2 * time.Hour), "sales_end": at(-time.Hour)})
+
+	want := map[string]string{"Upcoming": "upcoming", "Open": "open", "Ended": "closed"}
+	for _, event := range api.mustDo(http.StatusOK, "GET", "/events", "", nil)["events"].([]any) {
+		event := event.(map[string]any)
+		if status := event["sales_status"]; status != want[event["title"].(string)] {
+			t.Errorf("%s: listed sales_status = %v, want %s", event["title"], status, want[event["title"].(string)])
+		}
+	}
+	if status := api.mustDo(http.StatusOK, "GET", "/events/"+ended, "", nil)["sales_status"]; status != "closed" {
+		t.Errorf("ended: sales_status = %v, want closed", status)
+	}
+
+	book := map[string]any{"payment_token": "tok_ok"}
+	out := api.mustDo(http.StatusBadRequest, "POST", "/tickets/book/"+upcoming, buyer, book)
+	if out["error"] != "Ticket sales have not started yet" {
+		t.Errorf("before the window: error = %v", out["error"])
+	}
+	api.mustDo(http.StatusBadRequest, "POST", "/tickets/hold/"+upcoming, buyer, nil)
+	out = api.mustDo(http.StatusBadRequest, "POST", "/tickets/book/"+ended, buyer, book)
+	if out["error"] != "Ticket sales have ended" {
+		t.Errorf("after the window: error = %v", out["error"])
+	}
+	api.mustDo(http.StatusBadRequest, "POST", "/tickets/hold/"+ended, buyer, nil)
+	api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+open, buyer, book)
+
+	// Bookings follow the clock: the upcoming window opens, the open one closes
+	api.offers.Now = func() time.Time { return now.Add(90 * time.Minute) }
+	api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+upcoming, buyer, book)
+	api.mustDo(http.StatusBadRequest, "POST", "/tickets/book/"+open, buyer, book)
+}
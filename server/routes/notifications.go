@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"server/controllers"
+	"server/middleware"
+	"server/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupNotificationRoutes(r *gin.Engine, store *repository.Store) {
+	notificationController := controllers.NewNotificationController(store)
+	notifications := r.Group("/notifications")
+	{
+		notifications.GET("", middleware.AuthRequired(), notificationController.GetMyNotifications)
+	}
+}
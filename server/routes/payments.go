@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"server/controllers"
+	"server/payments"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupPaymentRoutes(r *gin.Engine, paymentService *payments.Service) {
+	paymentController := controllers.NewPaymentController(paymentService)
+	payment := r.Group("/payments")
+	{
+		// Called by the payment provider, authenticated by its signature
+		payment.POST("/webhook", paymentController.Webhook)
+	}
+}
@@ -0,0 +1,185 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"server/payments"
+	"server/repository"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// deliver posts a webhook the fake gateway queued and returns the status.
+func (api *testAPI) deliver(webhook payments.FakeWebhook) int {
+	api.t.Helper()
+
+	req := httptest.NewRequest("POST", "/payments/webhook", bytes.NewReader(webhook.Payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Payment-Signature", webhook.Signature)
+	w := httptest.NewRecorder()
+	api.router.ServeHTTP(w, req)
+	return w.Code
+}
+
+// ticketStatuses counts the event's tickets by status.
+func (api *testAPI) ticketStatuses(eventID string) map[string]int {
+	api.t.Helper()
+
+	objectID, _ := primitive.ObjectIDFromHex(eventID)
+	tickets, err := api.store.Tickets.ListByEvent(context.Background(), objectID)
+	if err != nil {
+		api.t.Fatal(err)
+	}
+	statuses := map[string]int{}
+	for _, ticket := range tickets {
+		statuses[ticket.Status]++
+	}
+	return statuses
+}
+
+func (api *testAPI) orderStatus(orderID string) string {
+	api.t.Helper()
+
+	objectID, _ := primitive.ObjectIDFromHex(orderID)
+	order, err := api.store.Orders.FindByID(context.Background(), objectID)
+	if err != nil {
+		api.t.Fatal(err)
+	}
+	return order.Status
+}
+
+func TestDeclinedPaymentReleasesSeats(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, map[string]any{"total_tickets": 3})
+
+	api.mustDo(http.StatusPaymentRequired, "POST", "/tickets/book/"+eventID, buyer, map[string]any{
+		"quantity":      2,
+		"payment_token": payments.FakeTokenDecline,
+	})
+	if got := api.availableTickets(eventID); got != 3 {
+		t.Errorf("available tickets = %d, want 3", got)
+	}
+	if got := api.ticketStatuses(eventID); got["pending"] != 0 || got["active"] != 0 {
+		t.Errorf("tickets left after a declined payment: %v", got)
+	}
+
+	// The seats can be bought again
+	api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{
+		"quantity":      3,
+		"payment_token": "tok_ok",
+	})
+}
+
+func TestFailedCaptureWebhook(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, map[string]any{"total_tickets": 3})
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{
+		"quantity":      2,
+		"payment_token": payments.FakeTokenFailing,
+	})
+	orderID := out["order_id"].(string)
+	if out["status"] != "pending" {
+		t.Fatalf("order status = %v, want pending", out["status"])
+	}
+	// The seats stay taken until the gateway reports back
+	if got := api.availableTickets(eventID); got != 1 {
+		t.Fatalf("available tickets = %d, want 1", got)
+	}
+
+	webhooks := api.fake.PendingWebhooks()
+	if len(webhooks) != 1 {
+		t.Fatalf("got %d webhooks, want 1", len(webhooks))
+	}
+	if code := api.deliver(webhooks[0]); code != http.StatusOK {
+		t.Fatalf("webhook: got status %d, want %d", code, http.StatusOK)
+	}
+	if got := api.orderStatus(orderID); got != "failed" {
+		t.Errorf("order status = %q, want failed", got)
+	}
+	if got := api.ticketStatuses(eventID); got["cancelled"] != 2 {
+		t.Errorf("ticket statuses = %v, want 2 cancelled", got)
+	}
+	if got := api.availableTickets(eventID); got != 3 {
+		t.Errorf("available tickets = %d, want 3", got)
+	}
+
+	// A late repeat of the delivery leaves the failed order alone
+	if code := api.deliver(webhooks[0]); code != http.StatusOK {
+		t.Fatalf("repeated webhook: got status %d, want %d", code, http.StatusOK)
+	}
+	if got := api.orderStatus(orderID); got != "failed" {
+		t.Errorf("order status = %q after a repeat, want failed", got)
+	}
+	if got := api.availableTickets(eventID); got != 3 {
+		t.Errorf("available tickets = %d after a repeat, want 3", got)
+	}
+
+	// Forged deliveries are rejected
+	forged := webhooks[0]
+	forged.Signature = "forged"
+	if code := api.deliver(forged); code != http.StatusUnauthorized {
+		t.Errorf("forged webhook: got status %d, want %d", code, http.StatusUnauthorized)
+	}
+}
+
+func TestDelayedCaptureWebhook(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{
+		"payment_token": payments.FakeTokenDelayed,
+	})
+	orderID := out["order_id"].(string)
+
+	for _, webhook := range api.fake.PendingWebhooks() {
+		api.deliver(webhook)
+	}
+	if got := api.orderStatus(orderID); got != "paid" {
+		t.Errorf("order status = %q, want paid", got)
+	}
+	if got := api.ticketStatuses(eventID); got["active"] != 1 {
+		t.Errorf("ticket statuses = %v, want 1 active", got)
+	}
+}
+
+// failingPaymentUpdates fails every change to a payment's status.
+type failingPaymentUpdates struct {
+	repository.PaymentRepository
+}
+
+func (r failingPaymentUpdates) UpdateStatus(ctx context.Context, id primitive.ObjectID, status string) error {
+	return errors.New("connection reset")
+}
+
+func TestCapturedPaymentCompletesOrder(t *testing.T) {
+	store := repository.NewMemoryStore()
+	store.Payments = failingPaymentUpdates{store.Payments}
+	api := newTestAPIWithStore(t, store)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	// The capture went through, so the order is paid even though the
+	// payment record could not be updated
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{
+		"quantity":      2,
+		"payment_token": "tok_ok",
+	})
+	if got := api.orderStatus(out["order_id"].(string)); got != "paid" {
+		t.Errorf("order status = %q, want paid", got)
+	}
+	if got := api.ticketStatuses(eventID); got["active"] != 2 {
+		t.Errorf("ticket statuses = %v, want 2 active", got)
+	}
+}
@@ -0,0 +1,200 @@
+package routes_test
+
+import (
+	"fmt"
+	"net/http"
+	"server/payments"
+	"sync"
+	"testing"
+	"time"
+)
+
+// createPromoCode adds a promo code to the event and returns it.
+func (api *testAPI) createPromoCode(organizer, eventID string, fields map[string]any) map[string]any {
+	api.t.Helper()
+
+	body := map[string]any{
+		"code":          "SPRING",
+		"discount_type": "percent",
+		"amount":        20,
+	}
+	for key, value := range fields {
+		body[key] = value
+	}
+	return api.mustDo(http.StatusCreated, "POST", "/events/"+eventID+"/promo-codes", organizer, body)
+}
+
+// promoUses returns how many tickets the event's promo code has discounted.
+func (api *testAPI) promoUses(organizer, eventID, code string) int {
+	api.t.Helper()
+
+	out := api.mustDo(http.StatusOK, "GET", "/events/"+eventID+"/promo-codes", organizer, nil)
+	for _, promo := range out["promo_codes"].([]any) {
+		promo := promo.(map[string]any)
+		if promo["code"] == code {
+			return int(promo["uses"].(float64))
+		}
+	}
+	api.t.Fatalf("promo code %s not found", code)
+	return 0
+}
+
+func TestPromoCodeValidityWindow(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	start := time.Now().Add(time.Hour)
+	api.createPromoCode(organizer, eventID, map[string]any{
+		"code":        "early",
+		"valid_from":  start.Format(time.RFC3339),
+		"valid_until": start.Add(time.Hour).Format(time.RFC3339),
+	})
+	book := map[string]any{"payment_token": "tok_ok", "promo_code": "EARLY"}
+
+	out := api.mustDo(http.StatusBadRequest, "POST", "/tickets/book/"+eventID, buyer, book)
+	if out["error"] != "Promo code is not valid at this time" {
+		t.Errorf("before the window: error = %v", out["error"])
+	}
+
+	api.offers.Now = func() time.Time { return start.Add(30 * time.Minute) }
+	out = api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, book)
+	if price := bookedTickets(out)[0]["price"]; price != 20.0 {
+		t.Errorf("discounted price = %v, want 20", price)
+	}
+
+	api.offers.Now = func() time.Time { return start.Add(2 * time.Hour) }
+	out = api.mustDo(http.StatusBadRequest, "POST", "/tickets/book/"+eventID, buyer, book)
+	if out["error"] != "Promo code is not valid at this time" {
+		t.Errorf("after the window: error = %v", out["error"])
+	}
+
+	if got := api.promoUses(organizer, eventID, "EARLY"); got != 1 {
+		t.Errorf("uses = %d, want 1", got)
+	}
+}
+
+func TestPromoCodeTicketTypes(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, map[string]any{
+		"ticket_types": []map[string]any{
+			{"name": "General", "price": 20, "total_tickets": 5},
+			{"name": "VIP", "price": 60, "total_tickets": 5},
+		},
+	})
+
+	types := api.ticketTypes(eventID)
+
+	api.createPromoCode(organizer, eventID, map[string]any{
+		"code":            "VIPHALF",
+		"amount":          50,
+		"ticket_type_ids": []any{types["VIP"]["id"]},
+	})
+
+	out := api.mustDo(http.StatusBadRequest, "POST", "/tickets/book/"+eventID, buyer, map[string]any{
+		"payment_token":  "tok_ok",
+		"ticket_type_id": types["General"]["id"],
+		"promo_code":     "VIPHALF",
+	})
+	if out["error"] != "Promo code does not apply to this ticket type" {
+		t.Errorf("error = %v", out["error"])
+	}
+
+	out = api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{
+		"payment_token":  "tok_ok",
+		"ticket_type_id": types["VIP"]["id"],
+		"promo_code":     "VIPHALF",
+	})
+	if out["total_price"] != 30.0 {
+		t.Errorf("total price = %v, want 30", out["total_price"])
+	}
+
+	// A code for another event's ticket type is refused outright
+	api.mustDo(http.StatusBadRequest, "POST", "/events/"+eventID+"/promo-codes", organizer, map[string]any{
+		"code":            "BOGUS",
+		"discount_type":   "fixed",
+		"amount":          5,
+		"ticket_type_ids": []string{eventID},
+	})
+}
+
+func TestPromoCodeUsageLimitUnderConcurrency(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	const limit, buyers = 3, 20
+	eventID := api.createEvent(organizer, map[string]any{"total_tickets": 50})
+	api.createPromoCode(organizer, eventID, map[string]any{"max_uses": limit})
+
+	tokens := make([]string, buyers)
+	for i := range tokens {
+		tokens[i] = api.register(fmt.Sprintf("buyer%d", i), "user")
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	statuses := map[int]int{}
+	for _, token := range tokens {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			code, _ := api.do("POST", "/tickets/book/"+eventID, token, map[string]any{"payment_token": "tok_ok", "promo_code": "SPRING"})
+			mu.Lock()
+			statuses[code]++
+			mu.Unlock()
+		}(token)
+	}
+	wg.Wait()
+
+	if statuses[http.StatusCreated] != limit || statuses[http.StatusConflict] != buyers-limit {
+		t.Errorf("statuses = %v, want %d created and %d conflicts", statuses, limit, buyers-limit)
+	}
+	if got := api.promoUses(organizer, eventID, "SPRING"); got != limit {
+		t.Errorf("uses = %d, want %d", got, limit)
+	}
+	// Refused bookings gave their seats back
+	if got := api.availableTickets(eventID); got != 50-limit {
+		t.Errorf("available tickets = %d, want %d", got, 50-limit)
+	}
+}
+
+func TestDeclinedPaymentReleasesPromoCode(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, nil)
+	api.createPromoCode(organizer, eventID, map[string]any{"max_uses": 2})
+
+	api.mustDo(http.StatusPaymentRequired, "POST", "/tickets/book/"+eventID, buyer, map[string]any{
+		"quantity":      2,
+		"payment_token": payments.FakeTokenDecline,
+		"promo_code":    "SPRING",
+	})
+	if got := api.promoUses(organizer, eventID, "SPRING"); got != 0 {
+		t.Fatalf("uses after a declined payment = %d, want 0", got)
+	}
+
+	// A capture that fails later gives the uses back as well
+	api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{
+		"quantity":      2,
+		"payment_token": payments.FakeTokenFailing,
+		"promo_code":    "SPRING",
+	})
+	if got := api.promoUses(organizer, eventID, "SPRING"); got != 2 {
+		t.Fatalf("uses while the capture is pending = %d, want 2", got)
+	}
+	for _, webhook := range api.fake.PendingWebhooks() {
+		api.deliver(webhook)
+	}
+	if got := api.promoUses(organizer, eventID, "SPRING"); got != 0 {
+		t.Fatalf("uses after a failed capture = %d, want 0", got)
+	}
+
+	api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{
+		"quantity":      2,
+		"payment_token": "tok_ok",
+		"promo_code":    "SPRING",
+	})
+}
@@ -0,0 +1,89 @@
+package routes_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"net/http"
+	"server/payments"
+	"server/utils"
+	"testing"
+)
+
+func TestTicketQRImages(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	other := api.register("other", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"quantity": 2, "payment_token": "tok_ok"})
+	tickets := bookedTickets(out)
+	ticketID, qrCode := tickets[0]["id"].(string), tickets[0]["qr_code"].(string)
+
+	w := api.fetch("/tickets/"+ticketID+"/qr.png?size=512", buyer)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("PNG: got status %d and type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if w.Header().Get("Cache-Control") != "private, no-store" {
+		t.Errorf("PNG is cacheable: %q", w.Header().Get("Cache-Control"))
+	}
+	image, err := png.Decode(bytes.NewReader(w.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("decoding PNG: %v", err)
+	}
+	if bounds := image.Bounds(); bounds.Dx() != 512 || bounds.Dy() != 512 {
+		t.Errorf("PNG is %dx%d, want 512x512", bounds.Dx(), bounds.Dy())
+	}
+	// The image encodes the ticket's own code
+	want, err := utils.RenderQRPNG(qrCode, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(w.Body.Bytes(), want) {
+		t.Error("PNG does not encode the ticket's QR code")
+	}
+	if w := api.fetch("/tickets/"+ticketID+"/qr.png", buyer); w.Code != http.StatusOK {
+		t.Errorf("default size: got status %d", w.Code)
+	}
+
+	w = api.fetch("/tickets/"+ticketID+"/qr.svg", buyer)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/svg+xml" {
+		t.Fatalf("SVG: got status %d and type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	var svg struct {
+		XMLName xml.Name
+		ViewBox string `xml:"viewBox,attr"`
+	}
+	if err := xml.Unmarshal(w.Body.Bytes(), &svg); err != nil || svg.XMLName.Local != "svg" || svg.ViewBox == "" {
+		t.Errorf("SVG is not a sized svg document (%v): %s", err, w.Body.String())
+	}
+	want, err = utils.RenderQRSVG(qrCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(w.Body.Bytes(), want) {
+		t.Error("SVG does not encode the ticket's QR code")
+	}
+
+	for _, size := range []string{"10", "4096", "big"} {
+		api.mustDo(http.StatusBadRequest, "GET", "/tickets/"+ticketID+"/qr.png?size="+size, buyer, nil)
+	}
+
+	// Only the holder of a valid ticket gets its code
+	for _, format := range []string{"qr.png", "qr.svg"} {
+		api.mustDo(http.StatusUnauthorized, "GET", "/tickets/"+ticketID+"/"+format, "", nil)
+		api.mustDo(http.StatusNotFound, "GET", "/tickets/"+ticketID+"/"+format, other, nil)
+	}
+	api.mustDo(http.StatusOK, "POST", "/tickets/"+tickets[1]["id"].(string)+"/cancel", buyer, nil)
+	out = api.mustDo(http.StatusBadRequest, "GET", "/tickets/"+tickets[1]["id"].(string)+"/qr.png", buyer, nil)
+	if out["error"] != "Ticket is cancelled" {
+		t.Errorf("cancelled ticket: error = %v", out["error"])
+	}
+
+	out = api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": payments.FakeTokenDelayed})
+	out = api.mustDo(http.StatusBadRequest, "GET", "/tickets/"+bookedTickets(out)[0]["id"].(string)+"/qr.svg", buyer, nil)
+	if out["error"] != "Ticket payment is still being processed" {
+		t.Errorf("pending ticket: error = %v", out["error"])
+	}
+}
@@ -0,0 +1,65 @@
+package routes_test
+
+import (
+	"net/http"
+	"server/payments"
+	"testing"
+)
+
+// payouts returns the caller's resale payouts.
+func (api *testAPI) payouts(token string) []map[string]any {
+	api.t.Helper()
+
+	out := api.mustDo(http.StatusOK, "GET", "/tickets/resale/payouts", token, nil)
+	var payouts []map[string]any
+	for _, payout := range out["payouts"].([]any) {
+		payouts = append(payouts, payout.(map[string]any))
+	}
+	return payouts
+}
+
+func TestResalePaysOutSeller(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	seller := api.register("seller", "user")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, map[string]any{"resale_enabled": true, "resale_fee": 2})
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, seller, map[string]any{"quantity": 2, "payment_token": "tok_ok"})
+	tickets := bookedTickets(out)
+
+	listing := api.mustDo(http.StatusCreated, "POST", "/tickets/"+tickets[0]["id"].(string)+"/resale", seller, map[string]any{"price": 20})
+	api.mustDo(http.StatusOK, "POST", "/tickets/resale/"+listing["id"].(string)+"/buy", buyer, map[string]any{"payment_token": "tok_ok"})
+
+	// The seller is owed the listing price; the fee is not theirs
+	payouts := api.payouts(seller)
+	if len(payouts) != 1 {
+		t.Fatalf("got %d payouts, want 1", len(payouts))
+	}
+	if payouts[0]["amount"] != 20.0 || payouts[0]["status"] != "pending" || payouts[0]["listing_id"] != listing["id"] {
+		t.Errorf("payout = %v, want a pending payout of 20 for the listing", payouts[0])
+	}
+	if got := api.payouts(buyer); len(got) != 0 {
+		t.Errorf("buyer has payouts %v", got)
+	}
+
+	// A delayed capture pays out once, however often its webhook arrives
+	listing = api.mustDo(http.StatusCreated, "POST", "/tickets/"+tickets[1]["id"].(string)+"/resale", seller, map[string]any{"price": 15})
+	api.mustDo(http.StatusAccepted, "POST", "/tickets/resale/"+listing["id"].(string)+"/buy", buyer, map[string]any{"payment_token": payments.FakeTokenDelayed})
+	if got := api.payouts(seller); len(got) != 1 {
+		t.Fatalf("paid out %d times before capture, want 1", len(got))
+	}
+	webhooks := api.fake.PendingWebhooks()
+	for i := 0; i < 2; i++ {
+		for _, webhook := range webhooks {
+			api.deliver(webhook)
+		}
+	}
+	payouts = api.payouts(seller)
+	if len(payouts) != 2 {
+		t.Fatalf("got %d payouts, want 2", len(payouts))
+	}
+	if payouts[0]["amount"] != 15.0 && payouts[1]["amount"] != 15.0 {
+		t.Errorf("payouts = %v, want one of 15", payouts)
+	}
+}
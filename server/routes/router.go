@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"server/payments"
+	"server/repository"
+	"server/waitlist"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewRouter wires every route against the given store. Passing
+// repository.NewMemoryStore() and a payments.FakeProvider gives a fully
+// working API with no database or payment gateway. offers must be the
+// waitlist service paymentService was built with.
+func NewRouter(store *repository.Store, paymentService *payments.Service, offers *waitlist.Service) *gin.Engine {
+	r := gin.Default()
+
+	// Add CORS middleware (optional)
+	r.Use(func(c *gin.Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization")
+
+		if c.Request.Method == "OPTIONS" {
+			c.AbortWithStatus(204)
+			return
+		}
+
+		c.Next()
+	})
+
+	// Setup routes
+	SetupAuthRoutes(r, store)
+	SetupEventRoutes(r, store, paymentService, offers)
+	SetupTicketRoutes(r, store, paymentService, offers)
+	SetupPaymentRoutes(r, paymentService)
+	SetupNotificationRoutes(r, store)
+	SetupStaffRoutes(r, store)
+
+	// Health check endpoint
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "OK", "message": "Event Ticketing API is running"})
+	})
+
+	return r
+}
@@ -0,0 +1,445 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"server/payments"
+	"server/repository"
+	"server/routes"
+	"server/utils"
+	"server/waitlist"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testAPI runs the full router against the in-memory store and the fake
+// payment gateway.
+type testAPI struct {
+	t        *testing.T
+	router   *gin.Engine
+	store    *repository.Store
+	fake     *payments.FakeProvider
+	payments *payments.Service
+	offers   *waitlist.Service
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	return newTestAPIWithStore(t, repository.NewMemoryStore())
+}
+
+// newTestAPIWithStore runs the router against store, which tests can wrap
+// to inject failures.
+func newTestAPIWithStore(t *testing.T, store *repository.Store) *testAPI {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	fake := payments.NewFakeProvider("test-webhook-secret", 0)
+	offers := waitlist.NewService(store, 30*time.Minute)
+	paymentService := payments.NewService(store, fake, "usd", offers)
+	return &testAPI{
+		t:        t,
+		router:   routes.NewRouter(store, paymentService, offers),
+		store:    store,
+		fake:     fake,
+		payments: paymentService,
+		offers:   offers,
+	}
+}
+
+// do sends a JSON request and decodes the JSON response body.
+func (api *testAPI) do(method, path, token string, body any) (int, map[string]any) {
+	api.t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			api.t.Fatalf("encoding request body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	api.router.ServeHTTP(w, req)
+
+	var out map[string]any
+	if w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+			api.t.Fatalf("%s %s: decoding response %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w.Code, out
+}
+
+// mustDo is do for requests that are expected to succeed with status.
+func (api *testAPI) mustDo(status int, method, path, token string, body any) map[string]any {
+	api.t.Helper()
+
+	code, out := api.do(method, path, token, body)
+	if code != status {
+		api.t.Fatalf("%s %s: got status %d, want %d: %v", method, path, code, status, out)
+	}
+	return out
+}
+
+// fetch sends a GET request and returns the raw response, for endpoints that
+// answer with something other than JSON or whose exact bytes matter.
+func (api *testAPI) fetch(path, token string) *httptest.ResponseRecorder {
+	api.t.Helper()
+
+	req := httptest.NewRequest("GET", path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	api.router.ServeHTTP(w, req)
+	return w
+}
+
+// register signs a new user up and returns their token.
+func (api *testAPI) register(name, role string) string {
+	api.t.Helper()
+
+	out := api.mustDo(http.StatusCreated, "POST", "/auth/register", "", map[string]any{
+		"name":     name,
+		"email":    name + "@example.com",
+		"password": "secret123",
+		"role":     role,
+	})
+	return out["token"].(string)
+}
+
+// createEvent creates a published event a year out and returns its ID.
+func (api *testAPI) createEvent(organizer string, fields map[string]any) string {
+	api.t.Helper()
+
+	body := map[string]any{
+		"title":         "Concert",
+		"date":          "2035-06-01T20:00:00Z",
+		"location":      "Main Hall",
+		"price":         25,
+		"total_tickets": 10,
+	}
+	for key, value := range fields {
+		body[key] = value
+	}
+	out := api.mustDo(http.StatusCreated, "POST", "/events", organizer, body)
+	return out["id"].(string)
+}
+
+func (api *testAPI) availableTickets(eventID string) int {
+	api.t.Helper()
+
+	out := api.mustDo(http.StatusOK, "GET", "/events/"+eventID, "", nil)
+	return int(out["available_tickets"].(float64))
+}
+
+// ticketTypes returns the event's ticket types by name.
+func (api *testAPI) ticketTypes(eventID string) map[string]map[string]any {
+	api.t.Helper()
+
+	types := map[string]map[string]any{}
+	out := api.mustDo(http.StatusOK, "GET", "/events/"+eventID, "", nil)
+	for _, ticketType := range out["ticket_types"].([]any) {
+		ticketType := ticketType.(map[string]any)
+		types[ticketType["name"].(string)] = ticketType
+	}
+	return types
+}
+
+// bookedTickets returns the tickets of a booking response.
+func bookedTickets(out map[string]any) []map[string]any {
+	var tickets []map[string]any
+	for _, ticket := range out["tickets"].([]any) {
+		tickets = append(tickets, ticket.(map[string]any))
+	}
+	return tickets
+}
+
+func TestBookTicket(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, map[string]any{"total_tickets": 3})
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{
+		"quantity":      2,
+		"payment_token": "tok_ok",
+	})
+	if out["status"] != "paid" {
+		t.Fatalf("order status = %v, want paid", out["status"])
+	}
+	tickets := bookedTickets(out)
+	if len(tickets) != 2 {
+		t.Fatalf("got %d tickets, want 2", len(tickets))
+	}
+	for _, ticket := range tickets {
+		if ticket["status"] != "active" {
+			t.Errorf("ticket status = %v, want active", ticket["status"])
+		}
+	}
+	if got := api.availableTickets(eventID); got != 1 {
+		t.Errorf("available tickets = %d, want 1", got)
+	}
+
+	code, out := api.do("POST", "/tickets/book/"+eventID, buyer, map[string]any{"quantity": 2, "payment_token": "tok_ok"})
+	if code != http.StatusBadRequest {
+		t.Fatalf("overbooking: got status %d, want %d: %v", code, http.StatusBadRequest, out)
+	}
+}
+
+func TestConcurrentBookingsDoNotOversell(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	const capacity, buyers = 5, 50
+	eventID := api.createEvent(organizer, map[string]any{"total_tickets": capacity})
+
+	tokens := make([]string, buyers)
+	for i := range tokens {
+		tokens[i] = api.register(fmt.Sprintf("buyer%d", i), "user")
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	statuses := map[int]int{}
+	for _, token := range tokens {
+		wg.Add(1)
+		go func(token string) {
+			defer wg.Done()
+			code, _ := api.do("POST", "/tickets/book/"+eventID, token, map[string]any{"payment_token": "tok_ok"})
+			mu.Lock()
+			statuses[code]++
+			mu.Unlock()
+		}(token)
+	}
+	wg.Wait()
+
+	if statuses[http.StatusCreated] != capacity {
+		t.Errorf("got %d successful bookings, want %d (statuses %v)", statuses[http.StatusCreated], capacity, statuses)
+	}
+	if statuses[http.StatusCreated]+statuses[http.StatusBadRequest]+statuses[http.StatusConflict] != buyers {
+		t.Errorf("unexpected statuses %v", statuses)
+	}
+	if got := api.availableTickets(eventID); got != 0 {
+		t.Errorf("available tickets = %d, want 0", got)
+	}
+
+	objectID, _ := primitive.ObjectIDFromHex(eventID)
+	tickets, err := api.store.Tickets.ListByEvent(context.Background(), objectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != capacity {
+		t.Errorf("issued %d tickets, want %d", len(tickets), capacity)
+	}
+}
+
+func TestCancelTicket(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	other := api.register("other", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	ticketID := bookedTickets(out)[0]["id"].(string)
+
+	// Other users are told the ticket does not exist
+	api.mustDo(http.StatusNotFound, "POST", "/tickets/"+ticketID+"/cancel", other, nil)
+
+	out = api.mustDo(http.StatusOK, "POST", "/tickets/"+ticketID+"/cancel", buyer, nil)
+	refund := out["refund"].(map[string]any)
+	if refund["status"] != "completed" || refund["amount"] != 25.0 {
+		t.Errorf("refund = %v, want a completed refund of 25", refund)
+	}
+	if got := api.availableTickets(eventID); got != 10 {
+		t.Errorf("available tickets = %d, want 10", got)
+	}
+
+	api.mustDo(http.StatusBadRequest, "POST", "/tickets/"+ticketID+"/cancel", buyer, nil)
+}
+
+func TestCancelDeadline(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+
+	api.mustDo(http.StatusBadRequest, "POST", "/events", organizer, map[string]any{
+		"title":                 "Concert",
+		"date":                  "2035-06-01T20:00:00Z",
+		"location":              "Main Hall",
+		"price":                 25,
+		"total_tickets":         10,
+		"cancel_deadline_hours": -1,
+	})
+
+	// A deadline further out than the event itself has already passed
+	eventID := api.createEvent(organizer, map[string]any{"cancel_deadline_hours": 100 * 365 * 24})
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	ticketID := bookedTickets(out)[0]["id"].(string)
+
+	api.mustDo(http.StatusBadRequest, "POST", "/tickets/"+ticketID+"/cancel", buyer, nil)
+	if got := api.availableTickets(eventID); got != 9 {
+		t.Errorf("available tickets = %d, want 9", got)
+	}
+}
+
+func TestValidateTicket(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	otherOrganizer := api.register("other", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	qrCode := bookedTickets(out)[0]["qr_code"].(string)
+
+	// Only the event's organizer and its staff may admit holders
+	api.mustDo(http.StatusForbidden, "POST", "/tickets/validate", otherOrganizer, map[string]any{"qr_code": qrCode})
+
+	out = api.mustDo(http.StatusOK, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": qrCode, "gate": "A"})
+	if out["message"] != "Ticket validated successfully" {
+		t.Errorf("message = %v", out["message"])
+	}
+
+	code, out := api.do("POST", "/tickets/validate", organizer, map[string]any{"qr_code": qrCode})
+	if code == http.StatusOK {
+		t.Fatalf("second scan was accepted: %v", out)
+	}
+	if out["used_gate"] != "A" {
+		t.Errorf("used_gate = %v, want A", out["used_gate"])
+	}
+
+	// A tampered code is turned away
+	api.mustDo(http.StatusBadRequest, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": qrCode[:len(qrCode)-4] + "AAAA"})
+}
+
+func TestValidateTicketFollowsEventDate(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	qrCode := bookedTickets(out)[0]["qr_code"].(string)
+
+	// Codes stop working a grace period after the event's current date,
+	// whatever date they were signed with
+	api.mustDo(http.StatusOK, "PUT", "/events/"+eventID, organizer, map[string]any{"date": "2020-06-01T20:00:00Z"})
+	out = api.mustDo(http.StatusBadRequest, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": qrCode})
+	if out["error"] != "QR code has expired" {
+		t.Errorf("error = %v, want QR code has expired", out["error"])
+	}
+
+	// A code issued before the event was postponed still admits its holder
+	api.mustDo(http.StatusOK, "PUT", "/events/"+eventID, organizer, map[string]any{"date": "2036-06-01T20:00:00Z"})
+	api.mustDo(http.StatusOK, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": qrCode})
+}
+
+func TestMultiDayEventTickets(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+
+	api.mustDo(http.StatusBadRequest, "POST", "/events", organizer, map[string]any{
+		"title": "Festival", "date": "2035-06-01T12:00:00Z", "end_date": "2035-05-30T12:00:00Z",
+		"location": "Park", "price": 25, "total_tickets": 10,
+	})
+	api.mustDo(http.StatusBadRequest, "POST", "/events", organizer, map[string]any{
+		"title": "Festival", "date": "2035-06-01T12:00:00Z", "timezone": "Mars/Olympus_Mons",
+		"location": "Park", "price": 25, "total_tickets": 10,
+	})
+
+	eventID := api.createEvent(organizer, map[string]any{
+		"date":     "2035-06-01T12:00:00Z",
+		"end_date": "2035-06-03T23:00:00Z",
+		"timezone": "Europe/Berlin",
+	})
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	qrCode := bookedTickets(out)[0]["qr_code"].(string)
+
+	// The code lasts until after the last day, not the first
+	payload, err := utils.ParseQRString(qrCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := utils.QRExpiry(time.Date(2035, 6, 3, 23, 0, 0, 0, time.UTC)); !payload.ExpiresAt.Equal(want) {
+		t.Errorf("code expires at %v, want %v", payload.ExpiresAt, want)
+	}
+
+	// An event that started long ago but is still running admits holders
+	api.mustDo(http.StatusOK, "PUT", "/events/"+eventID, organizer, map[string]any{"date": "2020-06-01T12:00:00Z"})
+	api.mustDo(http.StatusOK, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": qrCode})
+}
+
+func TestReentryExitAfterLastAdmission(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, map[string]any{"max_scans": 2})
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	qrCode := bookedTickets(out)[0]["qr_code"].(string)
+
+	for _, direction := range []string{"in", "out", "in", "out"} {
+		api.mustDo(http.StatusOK, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": qrCode, "direction": direction})
+	}
+
+	// Both admissions are spent, so the holder cannot come back in
+	code, out := api.do("POST", "/tickets/validate", organizer, map[string]any{"qr_code": qrCode, "direction": "in"})
+	if code == http.StatusOK {
+		t.Errorf("third admission was accepted: %v", out)
+	}
+}
+
+func TestConcurrentScansAdmitOnce(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	ticket := bookedTickets(out)[0]
+
+	// The same code shown at every gate at once
+	const gates = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	statuses := map[int]int{}
+	for i := 0; i < gates; i++ {
+		wg.Add(1)
+		go func(gate string) {
+			defer wg.Done()
+			code, _ := api.do("POST", "/tickets/validate", organizer, map[string]any{"qr_code": ticket["qr_code"], "gate": gate})
+			mu.Lock()
+			statuses[code]++
+			mu.Unlock()
+		}(fmt.Sprintf("gate-%d", i))
+	}
+	wg.Wait()
+
+	if statuses[http.StatusOK] != 1 || statuses[http.StatusConflict] != gates-1 {
+		t.Errorf("statuses = %v, want one admission and %d conflicts", statuses, gates-1)
+	}
+
+	objectID, _ := primitive.ObjectIDFromHex(ticket["id"].(string))
+	stored, err := api.store.Tickets.FindByID(context.Background(), objectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != "used" || stored.ScanCount != 1 || len(stored.Scans) != 1 {
+		t.Errorf("ticket is %s with %d scans counted and %d logged, want used with 1", stored.Status, stored.ScanCount, len(stored.Scans))
+	}
+}
@@ -0,0 +1,118 @@
+package routes_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"server/models"
+	"server/utils"
+	"testing"
+)
+
+func TestScanManifestIsSigned(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, map[string]any{"max_scans": 2})
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"quantity": 3, "payment_token": "tok_ok"})
+	tickets := bookedTickets(out)
+	api.mustDo(http.StatusOK, "POST", "/tickets/"+tickets[2]["id"].(string)+"/cancel", buyer, nil)
+
+	w := api.fetch("/tickets/events/"+eventID+"/manifest", organizer)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.Bytes()
+
+	// Scanners check the body against the public QR key before trusting it
+	signature, err := base64.StdEncoding.DecodeString(w.Header().Get("X-Manifest-Signature"))
+	if err != nil {
+		t.Fatalf("decoding signature: %v", err)
+	}
+	if !ed25519.Verify(utils.QRPublicKey(), body, signature) {
+		t.Fatal("manifest signature does not verify")
+	}
+	tampered := append([]byte(nil), body...)
+	tampered[len(tampered)/2] ^= 1
+	if ed25519.Verify(utils.QRPublicKey(), tampered, signature) {
+		t.Error("tampered manifest verifies")
+	}
+
+	var manifest models.ScanManifest
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.EventID.Hex() != eventID || manifest.MaxScans != 2 {
+		t.Errorf("manifest is for event %s with %d scans", manifest.EventID.Hex(), manifest.MaxScans)
+	}
+	// Cancelled tickets are left out
+	if len(manifest.Tickets) != 2 {
+		t.Fatalf("manifest lists %d tickets, want 2", len(manifest.Tickets))
+	}
+	for _, ticket := range manifest.Tickets {
+		if ticket.TicketID.Hex() == tickets[2]["id"] {
+			t.Errorf("manifest lists the cancelled ticket")
+		}
+	}
+}
+
+// offlineResults indexes the results of an offline scan upload by device.
+func offlineResults(out map[string]any) map[string]map[string]any {
+	results := map[string]map[string]any{}
+	for _, result := range out["results"].([]any) {
+		result := result.(map[string]any)
+		results[result["device_id"].(string)] = result
+	}
+	return results
+}
+
+func TestOfflineScanUpload(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"quantity": 2, "payment_token": "tok_ok"})
+	tickets := bookedTickets(out)
+	admitted, cancelled := tickets[0]["qr_code"].(string), tickets[1]["qr_code"].(string)
+	api.mustDo(http.StatusOK, "POST", "/tickets/"+tickets[1]["id"].(string)+"/cancel", buyer, nil)
+
+	// Uploaded out of order: the earlier of the two admissions wins
+	batch := map[string]any{"scans": []map[string]any{
+		{"qr_code": admitted, "device_id": "gate-2", "gate": "B", "scanned_at": "2035-06-01T19:05:00Z"},
+		{"qr_code": admitted, "device_id": "gate-1", "gate": "A", "scanned_at": "2035-06-01T19:00:00Z"},
+		{"qr_code": cancelled, "device_id": "gate-3", "scanned_at": "2035-06-01T19:10:00Z"},
+		{"qr_code": "TKT-unknown", "device_id": "gate-4", "scanned_at": "2035-06-01T19:15:00Z"},
+	}}
+	out = api.mustDo(http.StatusOK, "POST", "/tickets/events/"+eventID+"/offline-scans", organizer, batch)
+	results := offlineResults(out)
+	for device, want := range map[string]string{"gate-1": "accepted", "gate-2": "duplicate", "gate-3": "conflict", "gate-4": "invalid"} {
+		if got := results[device]["result"]; got != want {
+			t.Errorf("%s: result = %v, want %s (%v)", device, got, want, results[device])
+		}
+	}
+	if results["gate-2"]["used_gate"] != "A" {
+		t.Errorf("duplicate reports used_gate %v, want A", results["gate-2"]["used_gate"])
+	}
+
+	// The device retries the upload; nothing is applied twice
+	out = api.mustDo(http.StatusOK, "POST", "/tickets/events/"+eventID+"/offline-scans", organizer, batch)
+	results = offlineResults(out)
+	for device, want := range map[string]string{"gate-1": "synced", "gate-2": "synced", "gate-3": "synced", "gate-4": "invalid"} {
+		if got := results[device]["result"]; got != want {
+			t.Errorf("retry %s: result = %v, want %s", device, got, want)
+		}
+	}
+	summary := out["summary"].(map[string]any)
+	if summary["synced"] != 3.0 || summary["accepted"] != 0.0 {
+		t.Errorf("retry summary = %v", summary)
+	}
+
+	// The holder was admitted online as well as offline
+	out = api.mustDo(http.StatusConflict, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": admitted})
+	if out["used_gate"] != "A" {
+		t.Errorf("online scan reports used_gate %v, want A", out["used_gate"])
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"server/controllers"
+	"server/middleware"
+	"server/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupStaffRoutes(r *gin.Engine, store *repository.Store) {
+	staffController := controllers.NewStaffController(store)
+	staff := r.Group("/staff")
+	{
+		staff.POST("/invites/accept", middleware.AuthRequired(), middleware.RoleRequired("staff"), staffController.AcceptInvite)
+	}
+}
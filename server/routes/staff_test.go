@@ -0,0 +1,88 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestStaffInvite(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	out := api.mustDo(http.StatusCreated, "POST", "/events/"+eventID+"/staff", organizer, map[string]any{"email": "door@example.com"})
+	token := out["invite_token"].(string)
+	if token == "" {
+		t.Fatal("no invite token returned")
+	}
+
+	out = api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	qrCode := bookedTickets(out)[0]["qr_code"].(string)
+
+	// Registering with the invited email is not enough to scan
+	door := api.register("door", "staff")
+	api.mustDo(http.StatusForbidden, "POST", "/tickets/validate", door, map[string]any{"qr_code": qrCode})
+
+	// Nor is holding the token with another account
+	other := api.register("other", "staff")
+	api.mustDo(http.StatusNotFound, "POST", "/staff/invites/accept", other, map[string]any{"token": token})
+	api.mustDo(http.StatusNotFound, "POST", "/staff/invites/accept", door, map[string]any{"token": "wrong"})
+
+	out = api.mustDo(http.StatusOK, "POST", "/staff/invites/accept", door, map[string]any{"token": token})
+	if _, ok := out["invite_token"]; ok {
+		t.Errorf("accepted invite still shows its token: %v", out)
+	}
+	api.mustDo(http.StatusOK, "POST", "/tickets/validate", door, map[string]any{"qr_code": qrCode})
+
+	// The token is single use
+	api.mustDo(http.StatusNotFound, "POST", "/staff/invites/accept", door, map[string]any{"token": token})
+
+	// The staff listing never exposes tokens
+	out = api.mustDo(http.StatusOK, "GET", "/events/"+eventID+"/staff", organizer, nil)
+	for _, assignment := range out["staff"].([]any) {
+		if _, ok := assignment.(map[string]any)["invite_token"]; ok {
+			t.Errorf("staff listing exposes a token: %v", assignment)
+		}
+	}
+}
+
+// hireStaff invites a new staff member to the event and has them accept,
+// returning their token.
+func (api *testAPI) hireStaff(organizer, eventID, name string) string {
+	api.t.Helper()
+
+	out := api.mustDo(http.StatusCreated, "POST", "/events/"+eventID+"/staff", organizer, map[string]any{"email": name + "@example.com"})
+	staff := api.register(name, "staff")
+	api.mustDo(http.StatusOK, "POST", "/staff/invites/accept", staff, map[string]any{"token": out["invite_token"]})
+	return staff
+}
+
+func TestValidationScopedToEvent(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	otherOrganizer := api.register("other", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, nil)
+	otherEventID := api.createEvent(otherOrganizer, map[string]any{"title": "Other"})
+	otherStaff := api.hireStaff(otherOrganizer, otherEventID, "otherdoor")
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	qrCode := bookedTickets(out)[0]["qr_code"].(string)
+
+	// Neither the organizer nor the staff of another event may scan, fetch
+	// the manifest or upload offline scans for this one
+	for name, token := range map[string]string{"other organizer": otherOrganizer, "other staff": otherStaff} {
+		out := api.mustDo(http.StatusForbidden, "POST", "/tickets/validate", token, map[string]any{"qr_code": qrCode})
+		if out["error"] != "You are not allowed to validate tickets for this event" {
+			t.Errorf("%s: error = %v", name, out["error"])
+		}
+		api.mustDo(http.StatusForbidden, "GET", "/tickets/events/"+eventID+"/manifest", token, nil)
+		api.mustDo(http.StatusForbidden, "POST", "/tickets/events/"+eventID+"/offline-scans", token, map[string]any{
+			"scans": []map[string]any{{"qr_code": qrCode, "device_id": "gate-1", "scanned_at": "2035-06-01T19:00:00Z"}},
+		})
+	}
+
+	// The refused scans did not use the ticket up
+	api.mustDo(http.StatusOK, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": qrCode})
+}
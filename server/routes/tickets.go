@@ -3,19 +3,49 @@ package routes
 import (
 	"server/controllers"
 	"server/middleware"
+	"server/payments"
+	"server/repository"
+	"server/waitlist"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupTicketRoutes(r *gin.Engine) {
-	ticketController := &controllers.TicketController{}
+func SetupTicketRoutes(r *gin.Engine, store *repository.Store, paymentService *payments.Service, offers *waitlist.Service) {
+	ticketController := controllers.NewTicketController(store, paymentService, offers)
+	transferController := controllers.NewTransferController(store)
+	resaleController := controllers.NewResaleController(store, paymentService)
 	tickets := r.Group("/tickets")
 	{
+		// Public routes
+		tickets.GET("/qr-key", ticketController.GetQRPublicKey)
+		tickets.GET("/resale", resaleController.GetListings)
+
 		// User routes
 		tickets.POST("/book/:eventId", middleware.AuthRequired(), ticketController.BookTicket)
+		tickets.POST("/hold/:eventId", middleware.AuthRequired(), ticketController.HoldSeats)
+		tickets.POST("/holds/:id/checkout", middleware.AuthRequired(), ticketController.CheckoutHold)
+		tickets.DELETE("/holds/:id", middleware.AuthRequired(), ticketController.ReleaseHold)
 		tickets.GET("/my", middleware.AuthRequired(), ticketController.GetMyTickets)
+		tickets.POST("/:id/cancel", middleware.AuthRequired(), ticketController.CancelTicket)
+		tickets.POST("/:id/transfer", middleware.AuthRequired(), transferController.CreateTransfer)
+		tickets.GET("/transfers/incoming", middleware.AuthRequired(), transferController.GetIncomingTransfers)
+		tickets.POST("/transfers/:id/accept", middleware.AuthRequired(), transferController.AcceptTransfer)
+		tickets.POST("/transfers/:id/decline", middleware.AuthRequired(), transferController.DeclineTransfer)
+		tickets.POST("/transfers/:id/cancel", middleware.AuthRequired(), transferController.CancelTransfer)
+		tickets.POST("/:id/resale", middleware.AuthRequired(), resaleController.CreateListing)
+		tickets.POST("/resale/:id/buy", middleware.AuthRequired(), resaleController.BuyListing)
+		tickets.GET("/resale/payouts", middleware.AuthRequired(), resaleController.GetMyPayouts)
+		tickets.DELETE("/resale/:id", middleware.AuthRequired(), resaleController.CancelListing)
+		tickets.GET("/:id/qr.png", middleware.AuthRequired(), ticketController.GetTicketQRPNG)
+		tickets.GET("/:id/qr.svg", middleware.AuthRequired(), ticketController.GetTicketQRSVG)
+		tickets.GET("/:id/pdf", middleware.AuthRequired(), ticketController.GetTicketPDF)
+		tickets.GET("/:id/wallet/apple", middleware.AuthRequired(), ticketController.GetAppleWalletPass)
+		tickets.GET("/:id/wallet/google", middleware.AuthRequired(), ticketController.GetGoogleWalletLink)
+		tickets.GET("/orders/:id/pdf", middleware.AuthRequired(), ticketController.GetOrderPDF)
 
-		// Organizer routes
-		tickets.POST("/validate", middleware.AuthRequired(), middleware.RoleRequired("organizer"), ticketController.ValidateTicket)
+		// Organizer and door staff routes
+		tickets.POST("/validate", middleware.AuthRequired(), middleware.RoleRequired("organizer", "staff"), ticketController.ValidateTicket)
+		tickets.GET("/events/:eventId/manifest", middleware.AuthRequired(), middleware.RoleRequired("organizer", "staff"), ticketController.GetScanManifest)
+		tickets.POST("/events/:eventId/offline-scans", middleware.AuthRequired(), middleware.RoleRequired("organizer", "staff"), ticketController.UploadOfflineScans)
 	}
 }
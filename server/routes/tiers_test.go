@@ -0,0 +1,60 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTicketTypesHaveSeparateInventory(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	buyer := api.register("buyer", "user")
+	eventID := api.createEvent(organizer, map[string]any{
+		"ticket_types": []map[string]any{
+			{"name": "General", "price": 20, "total_tickets": 2},
+			{"name": "VIP", "price": 60, "total_tickets": 1},
+		},
+	})
+
+	event := api.mustDo(http.StatusOK, "GET", "/events/"+eventID, "", nil)
+	if event["total_tickets"] != 3.0 || event["available_tickets"] != 3.0 || event["price"] != 20.0 {
+		t.Errorf("event has %v of %v tickets from %v, want 3 of 3 from 20", event["available_tickets"], event["total_tickets"], event["price"])
+	}
+	types := api.ticketTypes(eventID)
+	general, vip := types["General"]["id"], types["VIP"]["id"]
+
+	out := api.mustDo(http.StatusBadRequest, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"payment_token": "tok_ok"})
+	if out["error"] != "A ticket type is required for this event" {
+		t.Errorf("booking without a type: error = %v", out["error"])
+	}
+
+	out = api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"quantity": 2, "ticket_type_id": general, "payment_token": "tok_ok"})
+	if out["total_price"] != 40.0 {
+		t.Errorf("general total = %v, want 40", out["total_price"])
+	}
+	generalTicket := bookedTickets(out)[0]
+	if generalTicket["ticket_type"] != "General" {
+		t.Errorf("ticket type = %v, want General", generalTicket["ticket_type"])
+	}
+
+	// General is sold out but VIP still has its own seat
+	out = api.mustDo(http.StatusBadRequest, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"ticket_type_id": general, "payment_token": "tok_ok"})
+	if out["error"] != "No tickets available" {
+		t.Errorf("sold out general: error = %v", out["error"])
+	}
+	out = api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"ticket_type_id": vip, "payment_token": "tok_ok"})
+	if out["total_price"] != 60.0 {
+		t.Errorf("VIP total = %v, want 60", out["total_price"])
+	}
+
+	// A cancelled seat goes back to its own type
+	api.mustDo(http.StatusOK, "POST", "/tickets/"+generalTicket["id"].(string)+"/cancel", buyer, nil)
+	types = api.ticketTypes(eventID)
+	if types["General"]["available_tickets"] != 1.0 || types["VIP"]["available_tickets"] != 0.0 {
+		t.Errorf("available general %v and VIP %v, want 1 and 0", types["General"]["available_tickets"], types["VIP"]["available_tickets"])
+	}
+	if got := api.availableTickets(eventID); got != 1 {
+		t.Errorf("available tickets = %d, want 1", got)
+	}
+	api.mustDo(http.StatusBadRequest, "POST", "/tickets/book/"+eventID, buyer, map[string]any{"ticket_type_id": vip, "payment_token": "tok_ok"})
+}
@@ -0,0 +1,73 @@
+package routes_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTransferRotatesQRCode(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	owner := api.register("owner", "user")
+	friend := api.register("friend", "user")
+	eventID := api.createEvent(organizer, nil)
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, owner, map[string]any{"payment_token": "tok_ok"})
+	ticket := bookedTickets(out)[0]
+	oldCode := ticket["qr_code"].(string)
+
+	transfer := api.mustDo(http.StatusCreated, "POST", "/tickets/"+ticket["id"].(string)+"/transfer", owner, map[string]any{"email": "friend@example.com"})
+	out = api.mustDo(http.StatusOK, "POST", "/tickets/transfers/"+transfer["id"].(string)+"/accept", friend, nil)
+	newCode := out["ticket"].(map[string]any)["qr_code"].(string)
+	if newCode == oldCode {
+		t.Fatal("transfer kept the QR code")
+	}
+
+	// A screenshot of the old code no longer gets anyone in
+	code, out := api.do("POST", "/tickets/validate", organizer, map[string]any{"qr_code": oldCode})
+	if code == http.StatusOK {
+		t.Fatalf("old code was accepted: %v", out)
+	}
+	api.mustDo(http.StatusOK, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": newCode})
+
+	// The previous owner has no say over the ticket any more
+	api.mustDo(http.StatusNotFound, "POST", "/tickets/"+ticket["id"].(string)+"/transfer", owner, map[string]any{"email": "owner@example.com"})
+}
+
+func TestTransferRefusedTickets(t *testing.T) {
+	api := newTestAPI(t)
+	organizer := api.register("organizer", "organizer")
+	owner := api.register("owner", "user")
+	friend := api.register("friend", "user")
+	eventID := api.createEvent(organizer, map[string]any{"resale_enabled": true})
+
+	out := api.mustDo(http.StatusCreated, "POST", "/tickets/book/"+eventID, owner, map[string]any{"quantity": 4, "payment_token": "tok_ok"})
+	tickets := bookedTickets(out)
+	used, cancelled, listed, pending := tickets[0], tickets[1], tickets[2], tickets[3]
+
+	api.mustDo(http.StatusOK, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": used["qr_code"]})
+	api.mustDo(http.StatusOK, "POST", "/tickets/"+cancelled["id"].(string)+"/cancel", owner, nil)
+	api.mustDo(http.StatusCreated, "POST", "/tickets/"+listed["id"].(string)+"/resale", owner, map[string]any{"price": 20})
+
+	for name, c := range map[string]struct {
+		ticket map[string]any
+		error  string
+	}{
+		"used":      {used, "Only active, unused tickets can be transferred"},
+		"cancelled": {cancelled, "Only active, unused tickets can be transferred"},
+		"listed":    {listed, "Ticket is listed for resale"},
+	} {
+		out := api.mustDo(http.StatusBadRequest, "POST", "/tickets/"+c.ticket["id"].(string)+"/transfer", owner, map[string]any{"email": "friend@example.com"})
+		if out["error"] != c.error {
+			t.Errorf("%s: error = %v, want %s", name, out["error"], c.error)
+		}
+	}
+
+	// A ticket used after the offer was made cannot be accepted
+	transfer := api.mustDo(http.StatusCreated, "POST", "/tickets/"+pending["id"].(string)+"/transfer", owner, map[string]any{"email": "friend@example.com"})
+	api.mustDo(http.StatusOK, "POST", "/tickets/validate", organizer, map[string]any{"qr_code": pending["qr_code"]})
+	out = api.mustDo(http.StatusConflict, "POST", "/tickets/transfers/"+transfer["id"].(string)+"/accept", friend, nil)
+	if out["error"] != "Ticket can no longer be transferred" {
+		t.Errorf("accepting a used ticket: error = %v", out["error"])
+	}
+}
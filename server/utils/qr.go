@@ -1,20 +1,129 @@
 package utils
 
 import (
+	"crypto/ed25519"
 	"crypto/rand"
-	"encoding/hex"
-	"fmt"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"server/config"
+	"strings"
+	"sync"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func GenerateQRString() string {
-	// Generate random bytes
-	bytes := make([]byte, 16)
-	rand.Read(bytes)
+// Signed QR codes look like "TKT1.<payload>.<signature>", both parts
+// unpadded base64url. The payload packs the ticket ID, event ID, expiry and
+// a random nonce so the code can be rotated without changing the ticket.
+const qrPrefix = "TKT1"
+
+const qrPayloadSize = 12 + 12 + 8 + 8
+
+var ErrInvalidQR = errors.New("invalid QR code")
+
+// The signing key and validity window are read from the configuration once,
+// on first use.
+var (
+	qrConfigOnce sync.Once
+	qrKey        ed25519.PrivateKey
+	qrValidity   time.Duration
+)
+
+type QRPayload struct {
+	TicketID  primitive.ObjectID
+	EventID   primitive.ObjectID
+	ExpiresAt time.Time
+}
+
+// GenerateQRString returns a new signed QR code for the ticket. Every call
+// yields a different code, so the old one can be invalidated by storing the
+// new one on the ticket.
+func GenerateQRString(ticketID, eventID primitive.ObjectID, expiresAt time.Time) string {
+	payload := make([]byte, qrPayloadSize)
+	copy(payload[0:12], ticketID[:])
+	copy(payload[12:24], eventID[:])
+	binary.BigEndian.PutUint64(payload[24:32], uint64(expiresAt.Unix()))
+	rand.Read(payload[32:40])
+
+	signature := ed25519.Sign(qrSigningKey(), payload)
+	return qrPrefix + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// NewTicketQR signs a fresh QR code for a ticket that stays valid until a
+// grace period after the event ends.
+func NewTicketQR(ticketID, eventID primitive.ObjectID, eventEnd time.Time) string {
+	return GenerateQRString(ticketID, eventID, QRExpiry(eventEnd))
+}
+
+// QRExpiry is when codes for an event ending at eventEnd stop admitting
+// holders. The expiry signed into a code only reflects the schedule at the
+// time it was issued, so scans are judged against the event's current one.
+func QRExpiry(eventEnd time.Time) time.Time {
+	loadQRConfig()
+	return eventEnd.Add(qrValidity)
+}
+
+// IsSignedQR reports whether the code uses the signed format rather than the
+// legacy random "TKT-<unix>-<hex>" one.
+func IsSignedQR(code string) bool {
+	return strings.HasPrefix(code, qrPrefix+".")
+}
+
+// ParseQRString checks the signature of a signed QR code without touching
+// the database. Its expiry is left to the caller, who knows whether the
+// event has moved since the code was issued.
+func ParseQRString(code string) (*QRPayload, error) {
+	parts := strings.Split(code, ".")
+	if len(parts) != 3 || parts[0] != qrPrefix {
+		return nil, ErrInvalidQR
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || len(payload) != qrPayloadSize {
+		return nil, ErrInvalidQR
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidQR
+	}
+
+	if !ed25519.Verify(QRPublicKey(), payload, signature) {
+		return nil, ErrInvalidQR
+	}
+
+	result := &QRPayload{
+		ExpiresAt: time.Unix(int64(binary.BigEndian.Uint64(payload[24:32])), 0),
+	}
+	copy(result.TicketID[:], payload[0:12])
+	copy(result.EventID[:], payload[12:24])
+	return result, nil
+}
+
+// SignManifest signs data with the QR key so scanners holding QRPublicKey can
+// trust manifests they were handed over an untrusted channel.
+func SignManifest(data []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(qrSigningKey(), data))
+}
+
+// QRPublicKey is handed to venue scanners so they can verify codes offline.
+func QRPublicKey() ed25519.PublicKey {
+	return qrSigningKey().Public().(ed25519.PublicKey)
+}
+
+func qrSigningKey() ed25519.PrivateKey {
+	loadQRConfig()
+	return qrKey
+}
 
-	// Create unique QR string with timestamp
-	timestamp := time.Now().Unix()
-	randomHex := hex.EncodeToString(bytes)
+func loadQRConfig() {
+	qrConfigOnce.Do(func() {
+		cfg := config.Load()
 
-	return fmt.Sprintf("TKT-%d-%s", timestamp, randomHex)
+		seed := sha256.Sum256([]byte(cfg.QRSigningSeed))
+		qrKey = ed25519.NewKeyFromSeed(seed[:])
+		qrValidity = cfg.QRValidityAfterEvent
+	})
 }
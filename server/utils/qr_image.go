@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QR codes are rendered with medium error correction, which leaves room for
+// print smudges without making the long signed codes too dense to scan.
+const qrRecoveryLevel = qrcode.Medium
+
+// RenderQRPNG renders the code as a square PNG of size pixels.
+func RenderQRPNG(code string, size int) ([]byte, error) {
+	return qrcode.Encode(code, qrRecoveryLevel, size)
+}
+
+// RenderQRSVG renders the code as a scalable SVG, one unit per module.
+func RenderQRSVG(code string) ([]byte, error) {
+	qr, err := qrcode.New(code, qrRecoveryLevel)
+	if err != nil {
+		return nil, err
+	}
+	bitmap := qr.Bitmap()
+	size := len(bitmap)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#ffffff"/>`, size, size)
+	buf.WriteString(`<path fill="#000000" d="`)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseQRString(t *testing.T) {
+	ticketID, eventID := primitive.NewObjectID(), primitive.NewObjectID()
+	expiresAt := time.Date(2035, 6, 2, 20, 0, 0, 0, time.UTC)
+
+	code := GenerateQRString(ticketID, eventID, expiresAt)
+	if !IsSignedQR(code) {
+		t.Fatalf("%q is not a signed code", code)
+	}
+	payload, err := ParseQRString(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload.TicketID != ticketID || payload.EventID != eventID || !payload.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	// Codes past their signed expiry still parse; the caller judges expiry
+	if _, err := ParseQRString(GenerateQRString(ticketID, eventID, time.Unix(0, 0))); err != nil {
+		t.Errorf("expired code: %v", err)
+	}
+
+	for _, bad := range []string{"", "TKT1", "TKT1.abc.def", code[:len(code)-4] + "AAAA", "TKT-123-abc"} {
+		if _, err := ParseQRString(bad); err != ErrInvalidQR {
+			t.Errorf("ParseQRString(%q) = %v, want ErrInvalidQR", bad, err)
+		}
+	}
+}
+
+func TestNewTicketQRExpiry(t *testing.T) {
+	eventDate := time.Date(2035, 6, 1, 20, 0, 0, 0, time.UTC)
+
+	payload, err := ParseQRString(NewTicketQR(primitive.NewObjectID(), primitive.NewObjectID(), eventDate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := QRExpiry(eventDate); !payload.ExpiresAt.Equal(want) {
+		t.Errorf("expires at %v, want %v", payload.ExpiresAt, want)
+	}
+	if !QRExpiry(eventDate).After(eventDate) {
+		t.Errorf("codes expire before the event starts")
+	}
+}
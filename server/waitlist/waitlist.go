@@ -0,0 +1,138 @@
+// Package waitlist hands seats that come free on a sold-out event to the
+// users queued for it, in the order they joined.
+package waitlist
+
+import (
+	"context"
+	"server/models"
+	"server/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Service turns available seats into offers. An offer is an ordinary hold
+// made on the waitlisted user's behalf, so it is checked out through the
+// hold endpoints and returned to inventory by the hold expirer when it
+// lapses, at which point the seats are offered to the next person.
+type Service struct {
+	events   repository.EventRepository
+	holds    repository.HoldRepository
+	entries  repository.WaitlistRepository
+	offerTTL time.Duration
+
+	// Now stamps offers and their expiry. Tests can replace it to drive the
+	// clock.
+	Now func() time.Time
+}
+
+func NewService(store *repository.Store, offerTTL time.Duration) *Service {
+	return &Service{
+		events:   store.Events,
+		holds:    store.Holds,
+		entries:  store.Waitlist,
+		offerTTL: offerTTL,
+		Now:      time.Now,
+	}
+}
+
+// OfferSeats offers available seats of the ticket type to waiting users
+// until the queue is empty or the next user's quantity no longer fits. It
+// returns how many offers were made. Nothing is offered while the event is
+// not on sale.
+func (s *Service) OfferSeats(ctx context.Context, eventID, ticketTypeID primitive.ObjectID) (int, error) {
+	event, err := s.events.FindByID(ctx, eventID)
+	if err != nil {
+		return 0, err
+	}
+	if event.SalesClosedReason(s.Now()) != "" {
+		return 0, nil
+	}
+
+	offered := 0
+	for {
+		entry, err := s.entries.NextWaiting(ctx, eventID, ticketTypeID)
+		if err == repository.ErrNotFound {
+			return offered, nil
+		}
+		if err != nil {
+			return offered, err
+		}
+
+		// Take the seats before claiming the entry so a concurrent booking
+		// cannot get them in between
+		err = s.events.ReserveTickets(ctx, eventID, ticketTypeID, entry.Quantity)
+		if err == repository.ErrSoldOut {
+			return offered, nil
+		}
+		if err != nil {
+			return offered, err
+		}
+
+		now := s.Now()
+		hold := models.Hold{
+			ID:           primitive.NewObjectID(),
+			EventID:      eventID,
+			UserID:       entry.UserID,
+			TicketTypeID: ticketTypeID,
+			Quantity:     entry.Quantity,
+			Status:       "active",
+			ExpiresAt:    now.Add(s.offerTTL),
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+
+		// The user may have left the queue since it was read
+		if err := s.entries.Offer(ctx, entry.ID, hold.ID, hold.ExpiresAt); err != nil {
+			s.events.ReleaseTickets(ctx, eventID, ticketTypeID, entry.Quantity)
+			if err == repository.ErrConflict {
+				continue
+			}
+			return offered, err
+		}
+
+		if err := s.holds.Create(ctx, &hold); err != nil {
+			s.entries.TransitionStatus(ctx, entry.ID, "offered", "waiting")
+			s.events.ReleaseTickets(ctx, eventID, ticketTypeID, entry.Quantity)
+			return offered, err
+		}
+		offered++
+	}
+}
+
+// OfferEventSeats offers the available seats of every ticket type of the
+// event, or of its single pool when it has none.
+func (s *Service) OfferEventSeats(ctx context.Context, event *models.Event) (int, error) {
+	if len(event.TicketTypes) == 0 {
+		return s.OfferSeats(ctx, event.ID, primitive.NilObjectID)
+	}
+
+	offered := 0
+	for _, ticketType := range event.TicketTypes {
+		n, err := s.OfferSeats(ctx, event.ID, ticketType.ID)
+		offered += n
+		if err != nil {
+			return offered, err
+		}
+	}
+	return offered, nil
+}
+
+// CloseOffer records what became of a hold if it was a waitlist offer:
+// "booked" once checked out, "declined" when given back, "expired" when it
+// lapsed. Holds that were not offers are ignored.
+func (s *Service) CloseOffer(ctx context.Context, holdID primitive.ObjectID, status string) error {
+	entry, err := s.entries.FindByHold(ctx, holdID)
+	if err == repository.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.entries.TransitionStatus(ctx, entry.ID, "offered", status)
+	if err == repository.ErrConflict {
+		return nil
+	}
+	return err
+}
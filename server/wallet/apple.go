@@ -0,0 +1,240 @@
+package wallet
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"server/config"
+	"server/utils"
+	"time"
+
+	"go.mozilla.org/pkcs7"
+)
+
+// AppleSigner builds .pkpass bundles signed with the pass type certificate.
+type AppleSigner struct {
+	passTypeID   string
+	teamID       string
+	organization string
+	cert         *x509.Certificate
+	key          crypto.PrivateKey
+	wwdr         *x509.Certificate
+}
+
+func NewAppleSigner(cfg *config.Config) (*AppleSigner, error) {
+	if cfg.ApplePassTypeID == "" || cfg.ApplePassCertFile == "" || cfg.ApplePassKeyFile == "" || cfg.AppleWWDRCertFile == "" {
+		return nil, ErrNotConfigured
+	}
+	// Wallet rejects passes whose team identifier does not match the
+	// certificate, so there is no point signing without it
+	if cfg.AppleTeamID == "" {
+		return nil, errors.New("APPLE_TEAM_ID is required for Apple Wallet passes")
+	}
+
+	cert, err := loadCertificate(cfg.ApplePassCertFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := loadPrivateKey(cfg.ApplePassKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	wwdr, err := loadCertificate(cfg.AppleWWDRCertFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppleSigner{
+		passTypeID:   cfg.ApplePassTypeID,
+		teamID:       cfg.AppleTeamID,
+		organization: cfg.WalletOrganization,
+		cert:         cert,
+		key:          key,
+		wwdr:         wwdr,
+	}, nil
+}
+
+type passField struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+	// DateStyle makes Wallet format ISO 8601 values in the viewer's locale
+	DateStyle string `json:"dateStyle,omitempty"`
+	TimeStyle string `json:"timeStyle,omitempty"`
+}
+
+type passBarcode struct {
+	Format          string `json:"format"`
+	Message         string `json:"message"`
+	MessageEncoding string `json:"messageEncoding"`
+	AltText         string `json:"altText,omitempty"`
+}
+
+type passStructure struct {
+	PrimaryFields   []passField `json:"primaryFields"`
+	SecondaryFields []passField `json:"secondaryFields"`
+	AuxiliaryFields []passField `json:"auxiliaryFields,omitempty"`
+	BackFields      []passField `json:"backFields,omitempty"`
+}
+
+type passJSON struct {
+	FormatVersion      int           `json:"formatVersion"`
+	PassTypeIdentifier string        `json:"passTypeIdentifier"`
+	SerialNumber       string        `json:"serialNumber"`
+	TeamIdentifier     string        `json:"teamIdentifier"`
+	OrganizationName   string        `json:"organizationName"`
+	Description        string        `json:"description"`
+	RelevantDate       string        `json:"relevantDate"`
+	ExpirationDate     string        `json:"expirationDate,omitempty"`
+	Voided             bool          `json:"voided,omitempty"`
+	Barcodes           []passBarcode `json:"barcodes"`
+	// Barcode is the pre-iOS 9 field, kept for older devices
+	Barcode         passBarcode   `json:"barcode"`
+	BackgroundColor string        `json:"backgroundColor"`
+	ForegroundColor string        `json:"foregroundColor"`
+	LabelColor      string        `json:"labelColor"`
+	EventTicket     passStructure `json:"eventTicket"`
+}
+
+type passFile struct {
+	name string
+	data []byte
+}
+
+// Bundle returns the signed .pkpass archive for the pass.
+func (s *AppleSigner) Bundle(pass Pass) ([]byte, error) {
+	barcode := passBarcode{
+		Format:          "PKBarcodeFormatQR",
+		Message:         pass.Ticket.QRCode,
+		MessageEncoding: "iso-8859-1",
+		AltText:         pass.Ticket.ID.Hex(),
+	}
+
+	details := passJSON{
+		FormatVersion:      1,
+		PassTypeIdentifier: s.passTypeID,
+		SerialNumber:       pass.Ticket.ID.Hex(),
+		TeamIdentifier:     s.teamID,
+		OrganizationName:   s.organization,
+		Description:        "Ticket for " + pass.Event.Title,
+		RelevantDate:       pass.Event.Date.UTC().Format(time.RFC3339),
+		ExpirationDate:     utils.QRExpiry(pass.Event.EndsAt()).UTC().Format(time.RFC3339),
+		Voided:             pass.voided(),
+		Barcodes:           []passBarcode{barcode},
+		Barcode:            barcode,
+		BackgroundColor:    "rgb(17, 24, 39)",
+		ForegroundColor:    "rgb(255, 255, 255)",
+		LabelColor:         "rgb(156, 163, 175)",
+		EventTicket: passStructure{
+			PrimaryFields: []passField{
+				{Key: "event", Label: "EVENT", Value: pass.Event.Title},
+			},
+			SecondaryFields: []passField{
+				{Key: "location", Label: "LOCATION", Value: pass.Event.Location},
+				{Key: "date", Label: "DATE", Value: pass.Event.Date.UTC().Format(time.RFC3339), DateStyle: "PKDateStyleMedium", TimeStyle: "PKDateStyleShort"},
+			},
+			AuxiliaryFields: []passField{
+				{Key: "holder", Label: "TICKET HOLDER", Value: pass.Holder.Name},
+			},
+			BackFields: []passField{
+				{Key: "description", Label: "About the event", Value: pass.Event.Description},
+				{Key: "ticket", Label: "Ticket number", Value: pass.Ticket.ID.Hex()},
+			},
+		},
+	}
+	if pass.Ticket.TicketType != "" {
+		details.EventTicket.AuxiliaryFields = append(details.EventTicket.AuxiliaryFields,
+			passField{Key: "type", Label: "TYPE", Value: pass.Ticket.TicketType})
+	}
+
+	passData, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	icon, err := passIcon(29)
+	if err != nil {
+		return nil, err
+	}
+	icon2x, err := passIcon(58)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []passFile{
+		{"pass.json", passData},
+		{"icon.png", icon},
+		{"icon@2x.png", icon2x},
+	}
+
+	// The manifest lists the SHA-1 of every file and is what gets signed
+	manifest := make(map[string]string)
+	for _, file := range files {
+		sum := sha1.Sum(file.data)
+		manifest[file.name] = hex.EncodeToString(sum[:])
+	}
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := s.sign(manifestData)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	files = append(files, passFile{"manifest.json", manifestData}, passFile{"signature", signature})
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(file.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sign produces the detached PKCS#7 signature Wallet expects for the
+// manifest, chained to the WWDR intermediate.
+func (s *AppleSigner) sign(manifest []byte) ([]byte, error) {
+	signedData, err := pkcs7.NewSignedData(manifest)
+	if err != nil {
+		return nil, err
+	}
+	signedData.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+	if err := signedData.AddSignerChain(s.cert, s.key, []*x509.Certificate{s.wwdr}, pkcs7.SignerInfoConfig{}); err != nil {
+		return nil, err
+	}
+	signedData.Detach()
+	return signedData.Finish()
+}
+
+// passIcon draws the plain square icon every pass must include.
+func passIcon(size int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	fill := color.RGBA{R: 17, G: 24, B: 39, A: 255}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
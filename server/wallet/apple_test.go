@@ -0,0 +1,124 @@
+package wallet
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"server/config"
+	"server/models"
+	"server/utils"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testAppleConfig writes a self-signed certificate and its key to a
+// temporary directory and returns a configuration that uses them for both
+// the pass certificate and the WWDR intermediate.
+func testAppleConfig(t *testing.T) *config.Config {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Pass Type ID: pass.com.example.tickets"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		// It stands in for the intermediate too, so it must be able to sign
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "pass.pem")
+	keyFile := filepath.Join(dir, "pass.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return &config.Config{
+		ApplePassTypeID:    "pass.com.example.tickets",
+		AppleTeamID:        "TEAM123456",
+		ApplePassCertFile:  certFile,
+		ApplePassKeyFile:   keyFile,
+		AppleWWDRCertFile:  certFile,
+		WalletOrganization: "Event Ticketing",
+	}
+}
+
+func TestNewAppleSignerConfiguration(t *testing.T) {
+	if _, err := NewAppleSigner(&config.Config{}); err != ErrNotConfigured {
+		t.Errorf("empty configuration: got %v, want ErrNotConfigured", err)
+	}
+
+	cfg := testAppleConfig(t)
+	cfg.AppleTeamID = ""
+	if _, err := NewAppleSigner(cfg); err == nil || err == ErrNotConfigured {
+		t.Errorf("missing team ID: got %v, want a configuration error", err)
+	}
+}
+
+func TestAppleBundleExpiresAfterEvent(t *testing.T) {
+	signer, err := NewAppleSigner(testAppleConfig(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := &models.Event{ID: primitive.NewObjectID(), Title: "Concert", Date: time.Date(2035, 6, 1, 20, 0, 0, 0, time.UTC)}
+	ticket := &models.Ticket{ID: primitive.NewObjectID(), EventID: event.ID, QRCode: "TKT-1-abc", Status: "active"}
+	bundle, err := signer.Bundle(Pass{Ticket: ticket, Event: event, Holder: &models.User{Name: "Holder"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var details passJSON
+	for _, file := range archive.File {
+		if file.Name != "pass.json" {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &details); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if details.TeamIdentifier != "TEAM123456" {
+		t.Errorf("teamIdentifier = %q", details.TeamIdentifier)
+	}
+	if want := utils.QRExpiry(event.Date).UTC().Format(time.RFC3339); details.ExpirationDate != want {
+		t.Errorf("expirationDate = %q, want %q", details.ExpirationDate, want)
+	}
+}
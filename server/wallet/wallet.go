@@ -0,0 +1,63 @@
+// Package wallet exports tickets as Apple Wallet and Google Wallet passes.
+package wallet
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"server/models"
+)
+
+// ErrNotConfigured is returned by the signer constructors when the
+// credentials for that wallet have not been set.
+var ErrNotConfigured = errors.New("wallet is not configured")
+
+// Pass holds everything shown on a wallet pass.
+type Pass struct {
+	Ticket *models.Ticket
+	Event  *models.Event
+	Holder *models.User
+}
+
+// voided reports whether the pass should show as no longer usable.
+func (p Pass) voided() bool {
+	return p.Ticket.Status == "used" || p.Ticket.Status == "cancelled"
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+	return block, nil
+}
+
+func loadCertificate(path string) (*x509.Certificate, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// loadPrivateKey accepts PKCS#8, PKCS#1 and SEC 1 encoded keys.
+func loadPrivateKey(path string) (crypto.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}